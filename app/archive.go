@@ -2,11 +2,172 @@ package app
 
 import (
 	"archive/zip"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+const (
+	ArchiveDir     = "Archive"
+	StatusArchived = "archived"
+)
+
+type ArchiveOptions struct {
+	// RemoveSource deletes the live project folder once the zip is verified.
+	RemoveSource bool
+}
+
+func ArchivePath(baseDir, id string) string {
+	return filepath.Join(baseDir, ArchiveDir, ValidateID(id)+".zip")
+}
+
+// ArchiveProject marks a project archived, zips it into BaseDir/Archive and
+// verifies the zip before optionally removing the live folder. The
+// project.yaml inside the zip already carries the archived status.
+func ArchiveProject(baseDir, id string, opts ArchiveOptions) (Project, error) {
+	p, err := ReadProjectFile(baseDir, id)
+	if err != nil {
+		return p, err
+	}
+	// The stored path may be stale; the rollback must go to the real file.
+	p.Path = filepath.Join(baseDir, p.ID)
+	original := p
+
+	moved, err := changeStatus(&p, StatusArchived)
+	if err != nil {
+		return original, err
+	}
+	if moved {
+		p.UpdatedAt = Timestamp()
+	}
+	if err := WriteProjectFile(p); err != nil {
+		return original, fmt.Errorf("write project file: %w", err)
+	}
+
+	// Journal before zipping so the archive carries its own record.
+	dest := ArchivePath(baseDir, p.ID)
+	entry := JournalEntry{Action: ActionArchive, Note: "archived to " + dest}
+	if moved {
+		entry.Changes = []FieldChange{{Field: "status", Old: original.Status, New: p.Status}}
+	}
+	if err := AppendJournal(p.Path, entry); err != nil {
+		return original, err
+	}
+
+	if err := zipAndVerify(p.Path, dest); err != nil {
+		if rbErr := WriteProjectFile(original); rbErr != nil {
+			err = errors.Join(err, fmt.Errorf("roll back project file: %w", rbErr))
+		}
+		note := JournalEntry{Action: ActionArchive, Note: "archive failed and was rolled back: " + err.Error()}
+		if entry.Changes != nil {
+			note.Changes = []FieldChange{{Field: "status", Old: p.Status, New: original.Status}}
+		}
+		if jErr := AppendJournal(p.Path, note); jErr != nil {
+			err = errors.Join(err, jErr)
+		}
+		return original, err
+	}
+
+	if opts.RemoveSource {
+		if err := os.RemoveAll(p.Path); err != nil {
+			return p, fmt.Errorf("remove project folder: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// zipAndVerify writes to a temporary file next to dest and only replaces
+// an older archive once the new one reads back cleanly.
+func zipAndVerify(sourceDir, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("create archive directory: %w", err)
+	}
+
+	tmp := dest + ".tmp"
+	if err := ZipProjectFolder(sourceDir, tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("zip project: %w", err)
+	}
+	if err := VerifyZip(tmp, sourceDir); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("verify archive: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("move archive into place: %w", err)
+	}
+	return nil
+}
+
+// RestoreProject unpacks BaseDir/Archive/<ID>.zip back into BaseDir/<ID>
+// and moves it to status, or to the first status the lifecycle allows after
+// archived when status is empty. The zip is kept.
+func RestoreProject(baseDir, id, status string) (Project, error) {
+	id = ValidateID(id)
+	src := ArchivePath(baseDir, id)
+	dest := filepath.Join(baseDir, id)
+
+	if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+		return Project{}, fmt.Errorf("%w: no archive for %s", ErrProjectNotFound, id)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		return Project{}, fmt.Errorf("%w: %s", ErrProjectExists, id)
+	}
+	if status == "" {
+		if next := AllowedTransitions(StatusArchived); len(next) > 0 {
+			status = next[0]
+		}
+	}
+	if status != "" {
+		var err error
+		if status, err = CanonicalStatus(status); err != nil {
+			return Project{}, err
+		}
+		if err := CheckTransition(StatusArchived, status); err != nil {
+			return Project{}, err
+		}
+	}
+
+	tmp := filepath.Join(baseDir, ".restore-"+id)
+	if err := os.RemoveAll(tmp); err != nil {
+		return Project{}, err
+	}
+	if err := unzip(src, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return Project{}, fmt.Errorf("unpack archive: %w", err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.RemoveAll(tmp)
+		return Project{}, fmt.Errorf("move project into place: %w", err)
+	}
+
+	p, err := ReadProjectFile(baseDir, id)
+	if err != nil {
+		return p, err
+	}
+	p.Path = dest
+	previous := p.Status
+	if status != "" {
+		if _, err := changeStatus(&p, status); err != nil {
+			return p, err
+		}
+	}
+	p.UpdatedAt = Timestamp()
+	if err := WriteProjectFile(p); err != nil {
+		return p, fmt.Errorf("write project file: %w", err)
+	}
+
+	entry := JournalEntry{Action: ActionRestore, Note: "restored from " + src}
+	if previous != p.Status {
+		entry.Changes = []FieldChange{{Field: "status", Old: previous, New: p.Status}}
+	}
+	return p, AppendJournal(p.Path, entry)
+}
+
 func ZipProjectFolder(sourceDir, destZip string) error {
 	zipFile, err := os.Create(destZip)
 	if err != nil {
@@ -15,13 +176,12 @@ func ZipProjectFolder(sourceDir, destZip string) error {
 	defer zipFile.Close()
 
 	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
 
-	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
+		if path == sourceDir {
 			return nil
 		}
 
@@ -30,13 +190,26 @@ func ZipProjectFolder(sourceDir, destZip string) error {
 			return err
 		}
 
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			// Keep empty folders such as Logs so a restore matches the original.
+			header.Name += "/"
+			_, err = zipWriter.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+
 		file, err := os.Open(path)
 		if err != nil {
 			return err
 		}
 		defer file.Close()
 
-		writer, err := zipWriter.Create(relPath)
+		writer, err := zipWriter.CreateHeader(header)
 		if err != nil {
 			return err
 		}
@@ -44,4 +217,109 @@ func ZipProjectFolder(sourceDir, destZip string) error {
 		_, err = io.Copy(writer, file)
 		return err
 	})
+	if err != nil {
+		zipWriter.Close()
+		return err
+	}
+	if err := zipWriter.Close(); err != nil {
+		return err
+	}
+	return zipFile.Close()
+}
+
+// VerifyZip reads every entry back (which checks its CRC) and makes sure
+// each regular file under sourceDir is present with the same size.
+func VerifyZip(zipPath, sourceDir string) error {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	sizes := make(map[string]uint64, len(r.File))
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		sizes[f.Name] = f.UncompressedSize64
+	}
+
+	if _, ok := sizes["project.yaml"]; !ok {
+		return fmt.Errorf("project.yaml missing from archive")
+	}
+
+	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		size, ok := sizes[name]
+		if !ok {
+			return fmt.Errorf("%s missing from archive", name)
+		}
+		if size != uint64(info.Size()) {
+			return fmt.Errorf("%s: archived %d bytes, expected %d", name, size, info.Size())
+		}
+		return nil
+	})
+}
+
+func unzip(src, dest string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		path := filepath.Join(dest, filepath.FromSlash(f.Name))
+		if path != dest && !strings.HasPrefix(path, dest+string(os.PathSeparator)) {
+			return fmt.Errorf("%s: entry escapes the project folder", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := extractFile(f, path); err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func extractFile(f *zip.File, path string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(path, f.Modified, f.Modified)
 }
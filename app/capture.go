@@ -0,0 +1,176 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCaptureIgnore is always skipped when capturing a preset, on top of
+// CaptureOptions.Ignore.
+var DefaultCaptureIgnore = []string{".git", ".svn", ".DS_Store", "Thumbs.db", "desktop.ini", "~$*", "*.tmp", "*.bak"}
+
+// DefaultCaptureMaxSize caps the files copied as templates, since presets
+// are meant for starter files rather than deliverables.
+const DefaultCaptureMaxSize = 64 << 10
+
+// CaptureOptions picks which files a captured preset brings along. Files and
+// Ignore hold glob patterns matched against both the slash-separated path
+// relative to the project and the base name.
+type CaptureOptions struct {
+	Files   []string
+	Ignore  []string
+	MaxSize int64
+	Force   bool
+}
+
+// CaptureResult describes a preset written by CapturePreset.
+type CaptureResult struct {
+	Preset  Preset
+	Path    string
+	Skipped []string
+}
+
+// CapturePreset turns an existing project into a preset: its folder tree,
+// plus the files selected by opts copied as templates. The project's ID and
+// name are replaced by {{.ID}} and {{.Name}} in folder names, file paths and
+// file contents so the preset can be reused.
+func CapturePreset(baseDir, id, name string, opts CaptureOptions) (CaptureResult, error) {
+	var r CaptureResult
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return r, fmt.Errorf("%w: %q is not a valid preset name", ErrInvalidPreset, name)
+	}
+	p, err := ReadProjectFile(baseDir, id)
+	if err != nil {
+		return r, err
+	}
+	root := filepath.Join(baseDir, ValidateID(id))
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultCaptureMaxSize
+	}
+	ignore := append(slices.Clone(DefaultCaptureIgnore), opts.Ignore...)
+
+	r.Path = filepath.Join(PresetsDir(baseDir), name+".yaml")
+	if _, _, err := readPresetFile(baseDir, name); err == nil && !opts.Force {
+		return r, fmt.Errorf("%w: %s", ErrPresetExists, name)
+	}
+
+	pairs := []string{}
+	if p.Name != "" {
+		pairs = append(pairs, p.Name, "{{.Name}}")
+	}
+	pairs = append(pairs, p.ID, "{{.ID}}")
+	templatize := strings.NewReplacer(pairs...)
+
+	r.Preset = Preset{Name: name}
+	var dirs []string
+	templates := map[string][]byte{}
+	err = filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchAny(ignore, rel) || rel == "project.yaml" || rel == TagRegistryFile || rel == path.Join(journalDir, journalFile) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			dirs = append(dirs, rel)
+			return nil
+		}
+		if !matchAny(opts.Files, rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > opts.MaxSize {
+			r.Skipped = append(r.Skipped, rel)
+			return nil
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		// Binary files and files that already use template syntax are
+		// copied as they are.
+		f := PresetFile{Path: templatize.Replace(rel), Template: rel}
+		if !utf8.Valid(content) || bytes.Contains(content, []byte("{{")) {
+			f.Raw = true
+		} else {
+			content = []byte(templatize.Replace(string(content)))
+		}
+		r.Preset.Files = append(r.Preset.Files, f)
+		templates[rel] = content
+		return nil
+	})
+	if err != nil {
+		return r, fmt.Errorf("walk project: %w", err)
+	}
+	r.Preset.Folders = folderTree(dirs, ".", templatize.Replace)
+
+	dir := filepath.Join(PresetsDir(baseDir), name)
+	if opts.Force {
+		if err := os.RemoveAll(dir); err != nil {
+			return r, err
+		}
+	}
+	for rel, content := range templates {
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return r, err
+		}
+		if err := os.WriteFile(dest, content, 0644); err != nil {
+			return r, err
+		}
+	}
+
+	data, err := yaml.Marshal(r.Preset)
+	if err != nil {
+		return r, err
+	}
+	if err := os.MkdirAll(PresetsDir(baseDir), 0755); err != nil {
+		return r, err
+	}
+	return r, writeFileAtomic(r.Path, data, 0644)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		for _, s := range []string{rel, path.Base(rel)} {
+			if ok, _ := path.Match(pattern, s); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// folderTree nests the slash-separated dirs found under parent.
+func folderTree(dirs []string, parent string, rename func(string) string) []Folder {
+	var folders []Folder
+	for _, d := range dirs {
+		if path.Dir(d) == parent {
+			folders = append(folders, Folder{Name: rename(path.Base(d)), Folders: folderTree(dirs, d, rename)})
+		}
+	}
+	return folders
+}
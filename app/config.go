@@ -1,15 +1,22 @@
 package app
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// ConfigDir is the folder inside the base directory holding projman's own
+// files, such as presets.
+const ConfigDir = "Config"
+
 type Config struct {
 	SoundsEnabled bool
 	BaseDir       string
@@ -21,21 +28,41 @@ type Config struct {
 	TaggingFormat string
 	TaggingStart  int
 	FolderPresets []string
-}
+	DefaultPreset string
+	// ProjectIDPattern describes how new project IDs look, e.g. CP-{seq:04}.
+	ProjectIDPattern string
+	// DesignationForm is how IEC 81346 designations are written on
+	// generated tags: DesignationShort or DesignationLong.
+	DesignationForm string
+
+	Statuses          []string
+	StatusTransitions map[string][]string
+	DefaultStatus     string
+
+	// Workspace is the active workspace, empty for the default one, whose
+	// settings have already been applied to the fields above.
+	Workspace  string
+	Workspaces []Workspace
 
-var env = map[string]string{
-	"PROJMAN_BASE_DIR":               "",
-	"PROJMAN_SOUND_ENABLED":          "",
-	"PROJMAN_SOUND_NAV_UP":           "",
-	"PROJMAN_SOUND_NAV_DOWN":         "",
-	"PROJMAN_SOUND_SELECT":           "",
-	"PROJMAN_SOUND_CONFIRM":          "",
-	"PROJMAN_SOUND_ERROR":            "",
-	"PROJMAN_TAGGING_FORMAT":         "",
-	"PROJMAN_TAGGING_START":          "",
-	"PROJMAN_PROJECT_FOLDER_PRESETS": "",
+	// Fields is the custom field schema for project.yaml.
+	Fields []FieldDef
 }
-var config = Config{
+
+// Where a config value came from, lowest precedence first.
+const (
+	OriginDefault   = "default"
+	OriginSystem    = "system"
+	OriginUser      = "user"
+	OriginLocal     = "local"
+	OriginWorkspace = "workspace"
+	OriginEnv       = "env"
+	OriginFlag      = "flag"
+)
+
+// SystemConfigPath is the machine-wide config file, read before the user's.
+var SystemConfigPath = "/etc/projman/config"
+
+var defaultConfig = Config{
 	SoundsEnabled: false,
 	BaseDir:       "",
 	NavUpSound:    "sounds/nav_up.wav",
@@ -45,47 +72,445 @@ var config = Config{
 	ConfirmSound:  "sounds/confirm.wav",
 	TaggingFormat: "{category}-{subcat}-{id}",
 	TaggingStart:  1,
+	DefaultPreset: DefaultPresetName,
+
+	DesignationForm: DesignationShort,
+
+	Statuses:          defaultStatuses,
+	StatusTransitions: defaultTransitions,
+	DefaultStatus:     "quote",
 }
 
-func SaveConfig(config Config) {
-	env["PROJMAN_BASE_DIR"] = config.BaseDir
-	env["PROJMAN_SOUND_ENABLED"] = strconv.FormatBool(config.SoundsEnabled)
-	env["PROJMAN_SOUND_NAV_UP"] = config.NavUpSound
-	env["PROJMAN_SOUND_NAV_DOWN"] = config.NavDownSound
-	env["PROJMAN_SOUND_SELECT"] = config.SelectSound
-	env["PROJMAN_SOUND_CONFIRM"] = config.ConfirmSound
-	env["PROJMAN_SOUND_ERROR"] = config.ErrorSound
-	env["PROJMAN_TAGGING_FORMAT"] = config.TaggingFormat
-	env["PROJMAN_TAGGING_START"] = strconv.Itoa(config.TaggingStart)
+// config is the configuration in effect, set by LoadConfig.
+var config = defaultConfig
+
+// origins records where each key in config was last set from, and
+// loadedFlags the flag layer it was loaded with, so a save can reload.
+var (
+	origins     = map[string]ConfigValue{}
+	loadedFlags map[string]string
+	// defaultBaseDir is the base directory outside of any workspace.
+	defaultBaseDir string
+)
+
+// setting maps one PROJMAN_* key onto a Config field.
+type setting struct {
+	key string
+	get func(Config) string
+	set func(*Config, string) error
+}
 
-	err := godotenv.Write(env, config.BaseDir+"Config/projman.conf")
+func stringSetting(key string, field func(*Config) *string) setting {
+	return setting{
+		key: key,
+		get: func(c Config) string { return *field(&c) },
+		set: func(c *Config, v string) error { *field(c) = v; return nil },
+	}
+}
+
+var settings = []setting{
+	{
+		key: "PROJMAN_BASE_DIR",
+		get: func(c Config) string { return c.BaseDir },
+		set: func(c *Config, v string) error { c.BaseDir = ExpandHome(v); return nil },
+	},
+	{
+		key: "PROJMAN_SOUND_ENABLED",
+		get: func(c Config) string { return strconv.FormatBool(c.SoundsEnabled) },
+		set: func(c *Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("%q is not true or false", v)
+			}
+			c.SoundsEnabled = b
+			return nil
+		},
+	},
+	stringSetting("PROJMAN_SOUND_NAV_UP", func(c *Config) *string { return &c.NavUpSound }),
+	stringSetting("PROJMAN_SOUND_NAV_DOWN", func(c *Config) *string { return &c.NavDownSound }),
+	stringSetting("PROJMAN_SOUND_SELECT", func(c *Config) *string { return &c.SelectSound }),
+	stringSetting("PROJMAN_SOUND_CONFIRM", func(c *Config) *string { return &c.ConfirmSound }),
+	stringSetting("PROJMAN_SOUND_ERROR", func(c *Config) *string { return &c.ErrorSound }),
+	stringSetting("PROJMAN_TAGGING_FORMAT", func(c *Config) *string { return &c.TaggingFormat }),
+	{
+		key: "PROJMAN_TAGGING_START",
+		get: func(c Config) string { return strconv.Itoa(c.TaggingStart) },
+		set: func(c *Config, v string) error {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", v)
+			}
+			c.TaggingStart = i
+			return nil
+		},
+	},
+	{
+		key: "PROJMAN_DESIGNATION_FORM",
+		get: func(c Config) string { return c.DesignationForm },
+		set: func(c *Config, v string) error {
+			if v != DesignationShort && v != DesignationLong {
+				return fmt.Errorf("%q is not %s or %s", v, DesignationShort, DesignationLong)
+			}
+			c.DesignationForm = v
+			return nil
+		},
+	},
+	{
+		key: "PROJMAN_PROJECT_FOLDER_PRESETS",
+		get: func(c Config) string { return strings.Join(c.FolderPresets, ",") },
+		set: func(c *Config, v string) error { c.FolderPresets = CleanTags(v); return nil },
+	},
+	stringSetting("PROJMAN_DEFAULT_PRESET", func(c *Config) *string { return &c.DefaultPreset }),
+	stringSetting("PROJMAN_PROJECT_ID_PATTERN", func(c *Config) *string { return &c.ProjectIDPattern }),
+	{
+		key: "PROJMAN_STATUSES",
+		get: func(c Config) string { return strings.Join(c.Statuses, ",") },
+		set: func(c *Config, v string) error { c.Statuses = CleanTags(v); return nil },
+	},
+	{
+		key: "PROJMAN_STATUS_TRANSITIONS",
+		get: func(c Config) string { return formatTransitions(c.StatusTransitions) },
+		set: func(c *Config, v string) error {
+			transitions, err := parseTransitions(v)
+			c.StatusTransitions = transitions
+			return err
+		},
+	},
+	stringSetting("PROJMAN_DEFAULT_STATUS", func(c *Config) *string { return &c.DefaultStatus }),
+}
+
+// ConfigValue is one resolved setting and the layer it came from. Path is
+// the file for file layers.
+type ConfigValue struct {
+	Key    string
+	Value  string
+	Origin string
+	Path   string
+}
+
+// UserConfigPath is $XDG_CONFIG_HOME/projman/config, or the platform's
+// equivalent when XDG_CONFIG_HOME is unset.
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
 	if err != nil {
-		fmt.Printf("Failed to save config: %s", err)
+		return "", err
 	}
+	return filepath.Join(dir, "projman", "config"), nil
 }
 
-func LoadConfig() Config {
-	if err := godotenv.Load(config.BaseDir + "Config/projman.conf"); err != nil {
-		log.Fatalf("Loading environment variables failed: %s", err)
+// LocalConfigPath is the config file kept with the projects themselves.
+func LocalConfigPath(baseDir string) string {
+	return filepath.Join(baseDir, ConfigDir, "projman.conf")
+}
+
+type configLayer struct {
+	origin string
+	path   string
+	values map[string]string
+}
+
+// LoadConfig resolves the configuration from, in increasing precedence, the
+// built-in defaults, SystemConfigPath, UserConfigPath, the local file in the
+// base directory, PROJMAN_* environment variables and flags, which hold
+// PROJMAN_* keys given on the command line. Missing files are skipped.
+func LoadConfig(flags map[string]string) (Config, error) {
+	var layers []configLayer
+	for _, l := range []configLayer{{origin: OriginSystem, path: SystemConfigPath}, {origin: OriginUser}} {
+		if l.origin == OriginUser {
+			path, err := UserConfigPath()
+			if err != nil {
+				continue
+			}
+			l.path = path
+		}
+		values, err := readConfigFile(l.path)
+		if err != nil {
+			return config, err
+		}
+		l.values = values
+		layers = append(layers, l)
+	}
+
+	envValues := map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		if v != "" && IsConfigKey(k) {
+			envValues[k] = v
+		}
+	}
+	env := configLayer{origin: OriginEnv, values: envValues}
+	flag := configLayer{origin: OriginFlag, values: flags}
+
+	// The local file lives in the base directory, so the base directory and
+	// workspace have to be settled from the other layers before it can be
+	// read, and it cannot change them.
+	outer := append(slices.Clone(layers), env, flag)
+	found := map[string]ConfigValue{}
+	workspaces, err := resolveWorkspaces(outer, found)
+	if err != nil {
+		return config, err
+	}
+	active, activeOrigin := lastValue(outer, "PROJMAN_WORKSPACE")
+	found[activeOrigin.Key] = activeOrigin
+	if active == DefaultWorkspaceName {
+		active = ""
 	}
 
-	config.BaseDir = os.Getenv("PROJMAN_BASE_DIR")
-	if config.BaseDir == "" {
-		config.BaseDir = GetDefaultBaseDir()
+	base, _ := lastValue(outer, "PROJMAN_BASE_DIR")
+	base = ExpandHome(base)
+	if base == "" {
+		if base, err = GetDefaultBaseDir(); err != nil {
+			return config, err
+		}
 	}
+	home := base
 
-	config.SoundsEnabled = strings.ToLower(os.Getenv("PROJMAN_SOUND_ENABLED")) == "true"
-	config.NavUpSound = os.Getenv("PROJMAN_SOUND_NAV_UP")
-	config.NavDownSound = os.Getenv("PROJMAN_SOUND_NAV_DOWN")
-	config.SelectSound = os.Getenv("PROJMAN_SOUND_SELECT")
-	config.ConfirmSound = os.Getenv("PROJMAN_SOUND_CONFIRM")
-	config.ErrorSound = os.Getenv("PROJMAN_SOUND_ERROR")
+	var workspace Workspace
+	if active != "" {
+		if workspace, err = findWorkspace(workspaces, active); err != nil {
+			return config, err
+		}
+		base = workspace.BaseDir
+	}
 
-	config.TaggingFormat = os.Getenv("PROJMAN_TAGGING_FORMAT")
-	if val := os.Getenv("PROJMAN_TAGGING_START"); val != "" {
-		if i, err := strconv.Atoi(val); err == nil {
-			config.TaggingStart = i
+	local := configLayer{origin: OriginLocal, path: LocalConfigPath(base)}
+	values, err := readConfigFile(local.path)
+	if err != nil {
+		return config, err
+	}
+	for k := range values {
+		if k == "PROJMAN_BASE_DIR" || isWorkspaceKey(k) {
+			delete(values, k)
+		}
+	}
+	local.values = values
+	layers = append(layers, local)
+	if active != "" {
+		layers = append(layers, workspace.layer())
+	}
+	layers = append(layers, env, flag)
+
+	c := defaultConfig
+	c.BaseDir = base
+	resolved := map[string]ConfigValue{}
+	for _, s := range settings {
+		resolved[s.key] = ConfigValue{Key: s.key, Origin: OriginDefault}
+	}
+	for _, l := range layers {
+		for _, s := range settings {
+			// An empty value leaves the setting to the layers below.
+			v := l.values[s.key]
+			if v == "" {
+				continue
+			}
+			if err := s.set(&c, v); err != nil {
+				return config, fmt.Errorf("%w: %s from %s: %v", ErrInvalidConfig, s.key, describeOrigin(l.origin, l.path), err)
+			}
+			resolved[s.key] = ConfigValue{Key: s.key, Origin: l.origin, Path: l.path}
+		}
+	}
+
+	if active != "" {
+		c.BaseDir = workspace.BaseDir
+		resolved["PROJMAN_BASE_DIR"] = ConfigValue{Key: "PROJMAN_BASE_DIR", Origin: OriginWorkspace, Path: active}
+	}
+	c.Workspace, c.Workspaces = active, workspaces
+	if c.Fields, err = resolveFields(layers, found); err != nil {
+		return config, err
+	}
+
+	// The built-in transitions only make sense for the built-in statuses.
+	if resolved["PROJMAN_STATUSES"].Origin != OriginDefault && resolved["PROJMAN_STATUS_TRANSITIONS"].Origin == OriginDefault {
+		c.StatusTransitions = nil
+	}
+	if err := validateLifecycle(c); err != nil {
+		return config, err
+	}
+
+	config, origins, loadedFlags = c, resolved, flags
+	extraOrigins, defaultBaseDir = found, home
+	return c, nil
+}
+
+// ConfigValues lists every setting in effect with the layer it came from.
+func ConfigValues() []ConfigValue {
+	values := make([]ConfigValue, len(settings))
+	for i, s := range settings {
+		v, ok := origins[s.key]
+		if !ok {
+			v = ConfigValue{Key: s.key, Origin: OriginDefault}
+		}
+		v.Value = s.get(config)
+		values[i] = v
+	}
+
+	names := make([]string, len(config.Workspaces))
+	for i, w := range config.Workspaces {
+		names[i] = w.Name
+	}
+	extra := func(key, value string) {
+		v := extraOrigins[key]
+		v.Key, v.Value = key, value
+		if v.Origin == "" {
+			v.Origin = OriginDefault
+		}
+		values = append(values, v)
+	}
+	extra("PROJMAN_WORKSPACE", config.Workspace)
+	extra("PROJMAN_WORKSPACES", strings.Join(names, ","))
+	for _, w := range config.Workspaces {
+		extra(WorkspaceKey(w.Name, "_BASE_DIR"), w.BaseDir)
+		extra(WorkspaceKey(w.Name, "_PRESETS"), strings.Join(w.FolderPresets, ","))
+		extra(WorkspaceKey(w.Name, "_TAGGING_FORMAT"), w.TaggingFormat)
+		extra(WorkspaceKey(w.Name, "_DEFAULT_PRESET"), w.DefaultPreset)
+	}
+
+	fieldNames := make([]string, len(config.Fields))
+	for i, d := range config.Fields {
+		fieldNames[i] = d.Name
+	}
+	extra("PROJMAN_FIELDS", strings.Join(fieldNames, ","))
+	for _, d := range config.Fields {
+		extra(FieldKey(d.Name, "_TYPE"), d.Type)
+		extra(FieldKey(d.Name, "_REQUIRED"), strconv.FormatBool(d.Required))
+		extra(FieldKey(d.Name, "_DEFAULT"), d.Default)
+		extra(FieldKey(d.Name, "_OPTIONS"), strings.Join(d.Options, "|"))
+	}
+	return values
+}
+
+// IsConfigKey reports whether key is a PROJMAN_* setting projman knows.
+func IsConfigKey(key string) bool {
+	for _, s := range settings {
+		if s.key == key {
+			return true
+		}
+	}
+	return isWorkspaceKey(key) || isFieldKey(key)
+}
+
+func (v ConfigValue) Source() string {
+	return describeOrigin(v.Origin, v.Path)
+}
+
+func describeOrigin(origin, path string) string {
+	if path == "" {
+		return origin
+	}
+	return fmt.Sprintf("%s (%s)", origin, path)
+}
+
+func readConfigFile(path string) (map[string]string, error) {
+	values, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: load %s: %v", ErrInvalidConfig, path, err)
+	}
+	return values, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// ValidateConfig checks a configuration before it is saved: the base
+// directory and any sound files must exist, the tagging format must parse
+// and the default preset and status must be usable.
+func ValidateConfig(c Config) error {
+	if info, err := os.Stat(c.BaseDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("%w: base directory %s does not exist", ErrInvalidConfig, c.BaseDir)
+	}
+	if c.SoundsEnabled {
+		for _, path := range []string{c.NavUpSound, c.NavDownSound, c.SelectSound, c.ConfirmSound, c.ErrorSound} {
+			if _, err := os.Stat(path); path != "" && err != nil {
+				return fmt.Errorf("%w: sound file %s does not exist", ErrInvalidConfig, path)
+			}
+		}
+	}
+	if err := ValidateTaggingFormat(c.TaggingFormat); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := ValidateIDPattern(c.ProjectIDPattern, c.Fields); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if c.TaggingStart < 0 {
+		return fmt.Errorf("%w: tagging start %d is negative", ErrInvalidConfig, c.TaggingStart)
+	}
+	if c.DefaultPreset != "" {
+		if _, err := LoadPreset(c.BaseDir, c.DefaultPreset); err != nil {
+			return fmt.Errorf("%w: default preset: %v", ErrInvalidConfig, err)
+		}
+	}
+	return validateLifecycle(c)
+}
+
+// SaveConfig validates c and writes the settings that differ from the
+// current configuration, then reloads it. Each key goes back to the file it
+// came from: the local file for keys set there, the active workspace's keys
+// for settings it overrides, and the user file for the rest. Values
+// still overridden by environment variables or flags win after the reload.
+func SaveConfig(c Config) (Config, error) {
+	c.BaseDir = ExpandHome(c.BaseDir)
+	if err := ValidateConfig(c); err != nil {
+		return config, err
+	}
+
+	userPath, err := UserConfigPath()
+	if err != nil {
+		return config, fmt.Errorf("save config: %w", err)
+	}
+	files := map[string]map[string]string{}
+	for _, s := range settings {
+		value := s.get(c)
+		if value == s.get(config) {
+			continue
+		}
+		key, path := s.key, userPath
+		switch o := origins[s.key]; o.Origin {
+		case OriginLocal:
+			path = o.Path
+		case OriginWorkspace:
+			// Keep the change inside the active workspace.
+			for _, ws := range workspaceSettings {
+				if ws.key == s.key {
+					key = WorkspaceKey(config.Workspace, ws.suffix)
+				}
+			}
+		}
+		if files[path] == nil {
+			values, err := readConfigFile(path)
+			if err != nil {
+				return config, err
+			}
+			if values == nil {
+				values = map[string]string{}
+			}
+			files[path] = values
+		}
+		files[path][key] = value
+	}
+
+	for path, values := range files {
+		data, err := godotenv.Marshal(values)
+		if err != nil {
+			return config, fmt.Errorf("save config: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return config, fmt.Errorf("save config: %w", err)
+		}
+		if err := writeFileAtomic(path, []byte(data+"\n"), 0644); err != nil {
+			return config, fmt.Errorf("save config: %w", err)
 		}
 	}
-	return config
+	return LoadConfig(loadedFlags)
 }
@@ -0,0 +1,340 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A reference designation in the style of IEC 81346 names an object from
+// up to three aspects: what it does (function, =), what it is (product, -)
+// and where it is (location, +). Each aspect is a path of segments, a
+// letter code with an optional number, separated by dots:
+//
+//	short: =CAT-FM0101+LINE4.BAY2
+//	long:  F=CAT/P=FM-0101/L=LINE-4.BAY-2
+//
+// Repeating an aspect's sign also starts the next level, so =A1=B2 is the
+// same as =A1.B2, and the -FM-0101 spelling is read as -FM0101.
+const (
+	DesignationShort = "short"
+	DesignationLong  = "long"
+)
+
+// Aspects in the order they are written.
+const (
+	AspectFunction = "function"
+	AspectProduct  = "product"
+	AspectLocation = "location"
+)
+
+var (
+	aspectSigns  = map[byte]string{'=': AspectFunction, '-': AspectProduct, '+': AspectLocation}
+	aspectLabels = map[string]string{"F": AspectFunction, "P": AspectProduct, "L": AspectLocation}
+	aspectOrder  = []string{AspectFunction, AspectProduct, AspectLocation}
+	segmentRe    = regexp.MustCompile(`^([A-Z]+)-?([0-9]*)$`)
+)
+
+func aspectSign(aspect string) string {
+	for sign, a := range aspectSigns {
+		if a == aspect {
+			return string(sign)
+		}
+	}
+	return ""
+}
+
+func aspectLabel(aspect string) string {
+	for label, a := range aspectLabels {
+		if a == aspect {
+			return label
+		}
+	}
+	return ""
+}
+
+// Segment is one level of an aspect, such as FM0101.
+type Segment struct {
+	Code   string
+	Number string
+}
+
+// Designation holds the three aspects; any of them may be empty.
+type Designation struct {
+	Function []Segment
+	Product  []Segment
+	Location []Segment
+}
+
+func (d *Designation) aspect(name string) *[]Segment {
+	switch name {
+	case AspectFunction:
+		return &d.Function
+	case AspectProduct:
+		return &d.Product
+	default:
+		return &d.Location
+	}
+}
+
+func parseSegment(s string) (Segment, error) {
+	m := segmentRe.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return Segment{}, fmt.Errorf("%q is not a letter code with an optional number", s)
+	}
+	return Segment{Code: m[1], Number: m[2]}, nil
+}
+
+// parseAspect reads the dotted segments of one aspect.
+func parseAspect(s string) ([]Segment, error) {
+	var segments []Segment
+	for _, part := range strings.Split(s, ".") {
+		seg, err := parseSegment(part)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// ParseDesignation reads a designation in either the short or the long
+// form.
+func ParseDesignation(s string) (Designation, error) {
+	var d Designation
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return d, fmt.Errorf("%w: empty designation", ErrInvalidDesignation)
+	}
+	fail := func(err error) (Designation, error) {
+		return Designation{}, fmt.Errorf("%w: %s: %v", ErrInvalidDesignation, s, err)
+	}
+
+	if label, _, ok := strings.Cut(s, "="); ok && aspectLabels[strings.ToUpper(label)] != "" {
+		for _, part := range strings.Split(s, "/") {
+			label, value, ok := strings.Cut(part, "=")
+			aspect := aspectLabels[strings.ToUpper(strings.TrimSpace(label))]
+			if !ok || aspect == "" {
+				return fail(fmt.Errorf("%q should look like F=..., P=... or L=...", part))
+			}
+			if len(*d.aspect(aspect)) > 0 {
+				return fail(fmt.Errorf("%s aspect given twice", aspect))
+			}
+			segments, err := parseAspect(value)
+			if err != nil {
+				return fail(err)
+			}
+			*d.aspect(aspect) = segments
+		}
+		return d, nil
+	}
+
+	if aspectSigns[s[0]] == "" {
+		return fail(fmt.Errorf("should start with =, - or +"))
+	}
+	var current string
+	done := map[string]bool{}
+	for i := 0; i < len(s); {
+		sign := s[i]
+		end := strings.IndexAny(s[i+1:], "=-+")
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += i + 1
+		}
+		value := s[i+1 : end]
+		aspect := aspectSigns[sign]
+		segments := d.aspect(aspect)
+
+		switch {
+		case aspect == current && value != "" && strings.Trim(value, "0123456789") == "" &&
+			len(*segments) > 0 && (*segments)[len(*segments)-1].Number == "":
+			// -FM-0101: the number of the previous segment.
+			(*segments)[len(*segments)-1].Number = value
+		case aspect != current && done[aspect]:
+			return fail(fmt.Errorf("%s aspect given twice", aspect))
+		default:
+			parsed, err := parseAspect(value)
+			if err != nil {
+				return fail(err)
+			}
+			*segments = append(*segments, parsed...)
+		}
+		if current != "" && current != aspect {
+			done[current] = true
+		}
+		current, i = aspect, end
+	}
+	return d, nil
+}
+
+func (d Designation) render(long bool) string {
+	var parts []string
+	for _, aspect := range aspectOrder {
+		segments := *d.aspect(aspect)
+		if len(segments) == 0 {
+			continue
+		}
+		texts := make([]string, len(segments))
+		for i, seg := range segments {
+			texts[i] = seg.Code + seg.Number
+			if long && seg.Number != "" {
+				texts[i] = seg.Code + "-" + seg.Number
+			}
+		}
+		if long {
+			parts = append(parts, aspectLabel(aspect)+"="+strings.Join(texts, "."))
+		} else {
+			parts = append(parts, aspectSign(aspect)+strings.Join(texts, "."))
+		}
+	}
+	if long {
+		return strings.Join(parts, "/")
+	}
+	return strings.Join(parts, "")
+}
+
+// Short renders the designation as =CAT-FM0101+LINE4.
+func (d Designation) Short() string {
+	return d.render(false)
+}
+
+// Long renders the designation as F=CAT/P=FM-0101/L=LINE-4.
+func (d Designation) Long() string {
+	return d.render(true)
+}
+
+// Format renders the designation in form, DesignationShort or
+// DesignationLong.
+func (d Designation) Format(form string) string {
+	return d.render(form == DesignationLong)
+}
+
+// AspectString renders a single aspect with its sign, e.g. -FM0101.
+func (d Designation) AspectString(aspect string) string {
+	var only Designation
+	*only.aspect(aspect) = *d.aspect(aspect)
+	return only.Short()
+}
+
+// ClassTable lists the letter codes allowed in each aspect, with what they
+// mean. An aspect without entries isn't checked.
+type ClassTable struct {
+	Function map[string]string `yaml:"function,omitempty"`
+	Product  map[string]string `yaml:"product,omitempty"`
+	Location map[string]string `yaml:"location,omitempty"`
+}
+
+// DefaultClassTable holds the main product classes of IEC 81346-2, used
+// when the tag rules don't define classes of their own. Two-letter
+// subclasses have to be listed to be accepted.
+var DefaultClassTable = ClassTable{
+	Product: map[string]string{
+		"A": "Two or more purposes or tasks",
+		"B": "Converting an input variable into a signal",
+		"C": "Storing",
+		"E": "Providing radiant or thermal energy",
+		"F": "Protecting directly",
+		"G": "Initiating a flow of energy or material",
+		"H": "Producing a new kind of material or product",
+		"K": "Processing signals or information",
+		"M": "Providing mechanical energy",
+		"P": "Presenting information",
+		"Q": "Controlled switching or varying of a flow",
+		"R": "Restricting or stabilizing",
+		"S": "Converting a manual action into a signal",
+		"T": "Converting while retaining the kind of energy or material",
+		"U": "Keeping objects in a defined position",
+		"V": "Processing matter",
+		"W": "Guiding or transporting",
+		"X": "Connecting",
+	},
+}
+
+func (t ClassTable) empty() bool {
+	return len(t.Function) == 0 && len(t.Product) == 0 && len(t.Location) == 0
+}
+
+func (t ClassTable) classes(aspect string) map[string]string {
+	switch aspect {
+	case AspectFunction:
+		return t.Function
+	case AspectProduct:
+		return t.Product
+	default:
+		return t.Location
+	}
+}
+
+// Validate checks every letter code in d against the class table.
+func (d Designation) Validate(table ClassTable) error {
+	for _, aspect := range aspectOrder {
+		classes := table.classes(aspect)
+		if len(classes) == 0 {
+			continue
+		}
+		for _, seg := range *d.aspect(aspect) {
+			if _, ok := classes[seg.Code]; !ok {
+				known := make([]string, 0, len(classes))
+				for code := range classes {
+					known = append(known, code)
+				}
+				sort.Strings(known)
+				return fmt.Errorf("%w: %s: unknown %s class %q (known: %s)",
+					ErrInvalidDesignation, d.Short(), aspect, seg.Code, strings.Join(known, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// LoadClassTable returns the class table from the tag rules that apply to
+// path, or DefaultClassTable when they have none.
+func LoadClassTable(path string) (ClassTable, error) {
+	rules, _, err := LoadTagRules(path)
+	if err != nil {
+		return ClassTable{}, err
+	}
+	if rules.Classes.empty() {
+		return DefaultClassTable, nil
+	}
+	return rules.Classes, nil
+}
+
+// designationColumns are the CSV columns that turn designations on in
+// GenerateTags. A location column alone doesn't: it is often free text
+// used elsewhere in the tag format.
+var designationColumns = []string{AspectFunction, AspectProduct}
+
+// tagDesignation builds a tag's designation from its function, product and
+// location columns. The function defaults to the category, and a product
+// code without a number takes the padded instance number. The product is
+// never made up from the subcat, whose codes rarely follow IEC 81346-2.
+func tagDesignation(values map[string]string, number string) (Designation, error) {
+	var d Designation
+	for _, aspect := range aspectOrder {
+		value := strings.TrimLeft(values[aspect], "=-+")
+		if label, rest, ok := strings.Cut(value, "="); ok && aspectLabels[strings.ToUpper(label)] == aspect {
+			value = rest
+		}
+		if value == "" && aspect == AspectFunction {
+			value = values["category"]
+		}
+		if value == "" {
+			continue
+		}
+		segments, err := parseAspect(value)
+		if err != nil {
+			return d, fmt.Errorf("%w: %s %v", ErrInvalidDesignation, aspect, err)
+		}
+		if last := &segments[len(segments)-1]; aspect == AspectProduct && last.Number == "" {
+			last.Number = number
+		}
+		*d.aspect(aspect) = segments
+	}
+	if len(d.Function)+len(d.Product)+len(d.Location) == 0 {
+		return d, fmt.Errorf("%w: no aspects", ErrInvalidDesignation)
+	}
+	return d, nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDesignationRoundTrip(t *testing.T) {
+	tests := []struct {
+		short, long string
+	}{
+		{"=CAT-FM0101", "F=CAT/P=FM-0101"},
+		{"=CAT-FM0101+LINE4", "F=CAT/P=FM-0101/L=LINE-4"},
+		{"=POL-VLV0203", "F=POL/P=VLV-0203"},
+		{"=A1.B2-K3+G1.R2", "F=A-1.B-2/P=K-3/L=G-1.R-2"},
+		{"-QM1", "P=QM-1"},
+		{"+LINE4.BAY2", "L=LINE-4.BAY-2"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDesignation(tt.short)
+		if err != nil {
+			t.Fatalf("ParseDesignation(%q): %v", tt.short, err)
+		}
+		if got := d.Short(); got != tt.short {
+			t.Errorf("%q: Short() = %q", tt.short, got)
+		}
+		if got := d.Long(); got != tt.long {
+			t.Errorf("%q: Long() = %q, want %q", tt.short, got, tt.long)
+		}
+
+		back, err := ParseDesignation(tt.long)
+		if err != nil {
+			t.Fatalf("ParseDesignation(%q): %v", tt.long, err)
+		}
+		if !reflect.DeepEqual(back, d) {
+			t.Errorf("%q parsed as %+v, want %+v", tt.long, back, d)
+		}
+	}
+}
+
+func TestParseDesignationSpellings(t *testing.T) {
+	tests := map[string]string{
+		"=CAT-FM-0101":      "=CAT-FM0101",
+		"=A1=B2":            "=A1.B2",
+		"-K3=A1":            "=A1-K3",
+		"f=cat/p=fm-0101":   "=CAT-FM0101",
+		"L=LINE4/F=CAT":     "=CAT+LINE4",
+		" =CAT-FM0101+L4 ":  "=CAT-FM0101+L4",
+		"=CAT-FM0101-VLV02": "=CAT-FM0101.VLV02",
+		"=HD-MTR0601-A":     "=HD-MTR0601.A",
+	}
+	for in, want := range tests {
+		d, err := ParseDesignation(in)
+		if err != nil {
+			t.Errorf("ParseDesignation(%q): %v", in, err)
+			continue
+		}
+		if got := d.Short(); got != want {
+			t.Errorf("ParseDesignation(%q).Short() = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, bad := range []string{"", "CAT", "=", "=CAT-", "=A-B=C", "F=CAT/F=POL", "X=CAT", "=CAT-0101", "=C@T"} {
+		if _, err := ParseDesignation(bad); !errors.Is(err, ErrInvalidDesignation) {
+			t.Errorf("ParseDesignation(%q) error = %v, want ErrInvalidDesignation", bad, err)
+		}
+	}
+}
+
+func TestDesignationValidate(t *testing.T) {
+	table := ClassTable{
+		Function: map[string]string{"CAT": "Catalyst", "POL": "Polyol"},
+		Product:  map[string]string{"FM": "Flow meter", "MTR": "Motor"},
+	}
+	for in, ok := range map[string]bool{
+		"=CAT-FM0101":       true,
+		"=POL-MTR0203+ANY1": true,
+		"=ISO-FM0101":       false,
+		"=CAT-VFD0101":      false,
+	} {
+		d, err := ParseDesignation(in)
+		if err != nil {
+			t.Fatalf("ParseDesignation(%q): %v", in, err)
+		}
+		if err := d.Validate(table); (err == nil) != ok {
+			t.Errorf("Validate(%q) = %v, want ok=%v", in, err, ok)
+		}
+	}
+
+	d, _ := ParseDesignation("-M1")
+	if err := d.Validate(DefaultClassTable); err != nil {
+		t.Errorf("default table rejects -M1: %v", err)
+	}
+}
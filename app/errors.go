@@ -0,0 +1,22 @@
+package app
+
+import "errors"
+
+// Errors returned by the app package. Callers should match them with
+// errors.Is, since most are wrapped with the offending ID or path.
+var (
+	ErrProjectExists      = errors.New("project already exists")
+	ErrProjectNotFound    = errors.New("project not found")
+	ErrInvalidProject     = errors.New("invalid project file")
+	ErrSchemaTooNew       = errors.New("project file is from a newer projman")
+	ErrInvalidID          = errors.New("invalid project ID")
+	ErrPresetNotFound     = errors.New("preset not found")
+	ErrInvalidPreset      = errors.New("invalid preset")
+	ErrPresetExists       = errors.New("preset already exists")
+	ErrInvalidConfig      = errors.New("invalid config")
+	ErrInvalidStatus      = errors.New("invalid status")
+	ErrInvalidTransition  = errors.New("status transition not allowed")
+	ErrInvalidField       = errors.New("invalid custom field")
+	ErrLocked             = errors.New("locked by another projman")
+	ErrInvalidDesignation = errors.New("invalid reference designation")
+)
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Custom field types.
+const (
+	FieldString = "string"
+	FieldInt    = "int"
+	FieldDate   = "date"
+	FieldEnum   = "enum"
+	FieldBool   = "bool"
+)
+
+// DateLayout is how date fields are written.
+const DateLayout = "2006-01-02"
+
+var fieldTypes = []string{FieldString, FieldInt, FieldDate, FieldEnum, FieldBool}
+
+// A FieldDef describes one custom project field. The schema is declared in
+// config with PROJMAN_FIELDS=name,... and per-field keys such as
+// PROJMAN_FIELD_<NAME>_TYPE; values live under fields: in project.yaml.
+type FieldDef struct {
+	Name     string
+	Type     string
+	Required bool
+	Default  string
+	// Options lists the allowed values of an enum field.
+	Options []string
+}
+
+var fieldNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var fieldSuffixes = []string{"_TYPE", "_REQUIRED", "_DEFAULT", "_OPTIONS"}
+
+// FieldKey is the config key for one property of a custom field, e.g.
+// FieldKey("po_number", "_TYPE") is PROJMAN_FIELD_PO_NUMBER_TYPE.
+func FieldKey(name, suffix string) string {
+	return "PROJMAN_FIELD_" + strings.ToUpper(name) + suffix
+}
+
+func isFieldKey(key string) bool {
+	if key == "PROJMAN_FIELDS" {
+		return true
+	}
+	if !strings.HasPrefix(key, "PROJMAN_FIELD_") {
+		return false
+	}
+	for _, suffix := range fieldSuffixes {
+		if strings.HasSuffix(key, suffix) && len(key) > len("PROJMAN_FIELD_")+len(suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFields reads the custom field schema from layers and records the
+// origin of each key in found.
+func resolveFields(layers []configLayer, found map[string]ConfigValue) ([]FieldDef, error) {
+	names, origin := lastValue(layers, "PROJMAN_FIELDS")
+	found[origin.Key] = origin
+
+	var defs []FieldDef
+	for _, name := range CleanTags(names) {
+		if !fieldNameRe.MatchString(name) {
+			return nil, fmt.Errorf("%w: custom field %q must be lowercase letters, digits and _", ErrInvalidConfig, name)
+		}
+		value := func(suffix string) string {
+			v, origin := lastValue(layers, FieldKey(name, suffix))
+			found[origin.Key] = origin
+			return v
+		}
+
+		d := FieldDef{Name: name, Type: strings.ToLower(value("_TYPE")), Default: value("_DEFAULT")}
+		if d.Type == "" {
+			d.Type = FieldString
+		}
+		if !slices.Contains(fieldTypes, d.Type) {
+			return nil, fmt.Errorf("%w: custom field %s has unknown type %q (want one of %s)",
+				ErrInvalidConfig, name, d.Type, strings.Join(fieldTypes, ", "))
+		}
+		if v := value("_REQUIRED"); v != "" {
+			required, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s=%q is not true or false", ErrInvalidConfig, FieldKey(name, "_REQUIRED"), v)
+			}
+			d.Required = required
+		}
+		if options := value("_OPTIONS"); options != "" {
+			d.Options = CleanTags(strings.ReplaceAll(options, "|", ","))
+		}
+		if d.Type == FieldEnum && len(d.Options) == 0 {
+			return nil, fmt.Errorf("%w: enum field %s needs %s", ErrInvalidConfig, name, FieldKey(name, "_OPTIONS"))
+		}
+		if d.Default != "" {
+			if _, err := d.Parse(d.Default); err != nil {
+				return nil, fmt.Errorf("%w: default for %s: %v", ErrInvalidConfig, name, err)
+			}
+		}
+		defs = append(defs, d)
+	}
+	return defs, nil
+}
+
+// Parse checks a value against the field's type and returns it in its
+// canonical form, e.g. bools as true/false.
+func (d FieldDef) Parse(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch d.Type {
+	case FieldInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not a whole number", d.Name, value)
+		}
+		return strconv.Itoa(n), nil
+	case FieldDate:
+		t, err := time.Parse(DateLayout, value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not a date like 2024-03-31", d.Name, value)
+		}
+		return t.Format(DateLayout), nil
+	case FieldBool:
+		switch strings.ToLower(value) {
+		case "yes", "y", "on":
+			value = "true"
+		case "no", "n", "off":
+			value = "false"
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %q is not true or false", d.Name, value)
+		}
+		return strconv.FormatBool(b), nil
+	case FieldEnum:
+		for _, o := range d.Options {
+			if strings.EqualFold(o, value) {
+				return o, nil
+			}
+		}
+		return "", fmt.Errorf("%s: %q is not one of %s", d.Name, value, strings.Join(d.Options, ", "))
+	}
+	return value, nil
+}
+
+// Hint describes what the field accepts, for prompts and placeholders.
+func (d FieldDef) Hint() string {
+	switch d.Type {
+	case FieldEnum:
+		return strings.Join(d.Options, "|")
+	case FieldDate:
+		return "YYYY-MM-DD"
+	case FieldBool:
+		return "true|false"
+	}
+	return d.Type
+}
+
+// CustomFields returns the configured custom field schema.
+func CustomFields() []FieldDef {
+	return config.Fields
+}
+
+// applyFields sets values on top of current and validates the result
+// against the schema. An empty value removes the field. Unknown names are
+// rejected; required fields only have to be present when create is set or
+// when the change touches them, so projects made before a field became
+// required can still be edited.
+func applyFields(current, values map[string]string, create bool) (map[string]string, error) {
+	fields := maps.Clone(current)
+	if fields == nil {
+		fields = map[string]string{}
+	}
+
+	for name, value := range values {
+		i := slices.IndexFunc(config.Fields, func(d FieldDef) bool { return d.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidField, name)
+		}
+		if strings.TrimSpace(value) == "" {
+			delete(fields, name)
+			continue
+		}
+		parsed, err := config.Fields[i].Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidField, err)
+		}
+		fields[name] = parsed
+	}
+
+	for _, d := range config.Fields {
+		_, touched := values[d.Name]
+		if _, ok := fields[d.Name]; !ok && create && d.Default != "" {
+			fields[d.Name], _ = d.Parse(d.Default)
+		}
+		if _, ok := fields[d.Name]; !ok && d.Required && (create || touched) {
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidField, d.Name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// fieldNames lists a project's custom fields in schema order, followed by
+// any no longer in the schema.
+func fieldNames(fields map[string]string) []string {
+	var names []string
+	for _, d := range config.Fields {
+		if _, ok := fields[d.Name]; ok {
+			names = append(names, d.Name)
+		}
+	}
+	var rest []string
+	for name := range fields {
+		if !slices.Contains(names, name) {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	return append(names, rest...)
+}
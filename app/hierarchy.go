@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The nested tag format groups tags as System → Equipment → Instrument,
+// optionally under a line:
+//
+//	Line4:
+//	  Catalyst:
+//	    SystemCode: CAT
+//	    Equipment:
+//	      CAT-PMP-0101:
+//	        Name: Catalyst pump
+//	        Subcat: PMP
+//	        Type: Pump
+//	        Motor:
+//	          Tag: CAT-MTR-0101
+//	          Subcat: MTR
+//	          Description: 1 HP, 480V, 3PH Motor
+//
+// Equipment is keyed by its tag and instruments by their name. Any other
+// CSV column shows up as a field under its header, after the designation
+// when there is one. GenerateTags writes it
+// when the CSV has a parent column and reads it back as input, taking tags
+// from the registry rather than the file.
+const (
+	treeSystemCode  = "SystemCode"
+	treeEquipment   = "Equipment"
+	treeName        = "Name"
+	treeSubcat      = "Subcat"
+	treeTag         = "Tag"
+	treeDesignation = "Designation"
+)
+
+// treeColumns are the columns that shape the tree rather than being
+// written as fields.
+var treeColumns = []string{"category", "subcat", "name", "parent", "system", "line", "designation"}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// treeChild returns the mapping under key in m, adding it if needed.
+func treeChild(m *yaml.Node, key string) *yaml.Node {
+	if v := treeValue(m, key); v != nil {
+		return v
+	}
+	v := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, scalarNode(key), v)
+	return v
+}
+
+func treeValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func treeSet(m *yaml.Node, key, value string) {
+	m.Content = append(m.Content, scalarNode(key), scalarNode(value))
+}
+
+// buildTagTree nests the generated tags. values holds each row's column
+// values after inheritance; rows without a tag were skipped.
+func buildTagTree(table tagTable, rows []TagRow, values []map[string]string, tags []string) (*yaml.Node, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	equipment := map[string]*yaml.Node{}
+
+	fields := func(node *yaml.Node, i int) {
+		if d := values[i]["designation"]; d != "" {
+			treeSet(node, treeDesignation, d)
+		}
+		for c, h := range table.headers {
+			if !slices.Contains(treeColumns, table.columns[c]) && values[i][table.columns[c]] != "" {
+				treeSet(node, h, values[i][table.columns[c]])
+			}
+		}
+	}
+
+	for i, r := range rows {
+		if tags[i] == "" || r.Parent != "" {
+			continue
+		}
+		level := root
+		if table.has("line") {
+			level = treeChild(root, values[i]["line"])
+		}
+		name := values[i]["system"]
+		if name == "" {
+			name = r.Category
+		}
+		system := treeChild(level, name)
+		if code := treeValue(system, treeSystemCode); code == nil {
+			treeSet(system, treeSystemCode, r.Category)
+		} else if code.Value != r.Category {
+			return nil, fmt.Errorf("system %s has both %s and %s as its code", name, code.Value, r.Category)
+		}
+
+		e := treeChild(treeChild(system, treeEquipment), tags[i])
+		treeSet(e, treeName, r.Name)
+		treeSet(e, treeSubcat, r.Subcat)
+		fields(e, i)
+		equipment[r.Name] = e
+	}
+
+	for i, r := range rows {
+		if tags[i] == "" || r.Parent == "" {
+			continue
+		}
+		e := equipment[r.Parent]
+		if treeValue(e, r.Name) != nil {
+			return nil, fmt.Errorf("%s has two entries named %s", r.Parent, r.Name)
+		}
+		c := treeChild(e, r.Name)
+		treeSet(c, treeTag, tags[i])
+		treeSet(c, treeSubcat, r.Subcat)
+		fields(c, i)
+	}
+	return root, nil
+}
+
+// readTagTree reads the nested format back into rows for GenerateTags.
+func readTagTree(path string) (tagTable, error) {
+	t := tagTable{headers: []string{"Category", "Subcat", "Name", "Parent", "System"}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return t, fmt.Errorf("read yaml %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return t, fmt.Errorf("%s is not a System → Equipment → Instrument tag file", path)
+	}
+	fail := func(n *yaml.Node, msg string, args ...any) error {
+		return fmt.Errorf("%s line %d: %s", path, n.Line, fmt.Sprintf(msg, args...))
+	}
+
+	type record struct {
+		line   int
+		values map[string]string
+	}
+	var records []record
+	// fields reads the scalar fields of an equipment or instrument entry
+	// into rec and returns its instruments.
+	fields := func(n *yaml.Node, rec map[string]string) []*yaml.Node {
+		var children []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			switch {
+			case v.Kind == yaml.MappingNode:
+				children = append(children, k, v)
+			case k.Value == treeTag || k.Value == treeDesignation || v.Kind != yaml.ScalarNode:
+			case k.Value == treeName || k.Value == treeSubcat:
+				rec[k.Value] = v.Value
+			default:
+				if !slices.Contains(t.headers, k.Value) {
+					t.headers = append(t.headers, k.Value)
+				}
+				rec[k.Value] = v.Value
+			}
+		}
+		return children
+	}
+
+	readSystem := func(line, name string, system *yaml.Node) error {
+		code := treeValue(system, treeSystemCode)
+		if code == nil || code.Value == "" {
+			return fail(system, "system %s has no %s", name, treeSystemCode)
+		}
+		equipment := treeValue(system, treeEquipment)
+		if equipment == nil {
+			return nil
+		}
+		for i := 0; i+1 < len(equipment.Content); i += 2 {
+			key, e := equipment.Content[i], equipment.Content[i+1]
+			if e.Kind != yaml.MappingNode {
+				return fail(e, "equipment %s should be a mapping", key.Value)
+			}
+			rec := map[string]string{"Category": code.Value, "System": name, "Line": line, treeName: key.Value}
+			children := fields(e, rec)
+			if rec[treeSubcat] == "" {
+				return fail(e, "equipment %s has no %s", key.Value, treeSubcat)
+			}
+			records = append(records, record{e.Line, rec})
+
+			for j := 0; j < len(children); j += 2 {
+				ck, c := children[j], children[j+1]
+				child := map[string]string{"Category": code.Value, "System": name, "Line": line, "Parent": rec[treeName], treeName: ck.Value}
+				fields(c, child)
+				if child[treeSubcat] == "" {
+					return fail(c, "%s of %s has no %s", ck.Value, key.Value, treeSubcat)
+				}
+				records = append(records, record{c.Line, child})
+			}
+		}
+		return nil
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		if v.Kind != yaml.MappingNode {
+			return t, fail(v, "%s should be a system or a line of systems", k.Value)
+		}
+		if treeValue(v, treeSystemCode) != nil {
+			if err := readSystem("", k.Value, v); err != nil {
+				return t, err
+			}
+			continue
+		}
+		if !slices.Contains(t.headers, "Line") {
+			t.headers = append(t.headers, "Line")
+		}
+		for j := 0; j+1 < len(v.Content); j += 2 {
+			if err := readSystem(k.Value, v.Content[j].Value, v.Content[j+1]); err != nil {
+				return t, err
+			}
+		}
+	}
+
+	t.columns = make([]string, len(t.headers))
+	for i, h := range t.headers {
+		t.columns[i] = TagColumn(h)
+	}
+	for _, rec := range records {
+		row := make([]string, len(t.headers))
+		for i, h := range t.headers {
+			row[i] = rec.values[h]
+		}
+		t.rows = append(t.rows, row)
+		t.lines = append(t.lines, rec.line)
+	}
+	return t, nil
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The index caches every project.yaml under BaseDir/.projman/index so
+// listings only stat each file instead of reading and parsing it. An entry
+// is trusted while the file's mtime and size are unchanged.
+const (
+	indexDirName = ".projman"
+	indexVersion = 3
+)
+
+type projectIndex struct {
+	Version int                   `json:"version"`
+	Entries map[string]indexEntry `json:"entries"`
+}
+
+type indexEntry struct {
+	ModTime int64   `json:"mtime"`
+	Size    int64   `json:"size"`
+	Project Project `json:"project"`
+}
+
+func indexPath(baseDir string) string {
+	return filepath.Join(baseDir, indexDirName, "index")
+}
+
+func loadIndex(baseDir string) projectIndex {
+	idx := projectIndex{Version: indexVersion, Entries: map[string]indexEntry{}}
+	data, err := os.ReadFile(indexPath(baseDir))
+	if err != nil {
+		return idx
+	}
+
+	var stored projectIndex
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Version != indexVersion || stored.Entries == nil {
+		return idx
+	}
+	return stored
+}
+
+func saveIndex(baseDir string, idx projectIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	path := indexPath(baseDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// writeFileAtomic writes through a temporary file and renames it over path
+// so readers never see a half-written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// scanProjects lists the project folders in baseDir, using and refreshing
+// the on-disk index. With rebuild set, every project.yaml is re-read.
+// Folders that can't be read are skipped and described in the warnings,
+// which the caller shows however suits it.
+func scanProjects(baseDir string, rebuild bool) ([]Project, []string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read base directory: %w", err)
+	}
+
+	idx := projectIndex{Version: indexVersion, Entries: map[string]indexEntry{}}
+	if !rebuild {
+		idx = loadIndex(baseDir)
+	}
+	changed := rebuild
+
+	seen := make(map[string]bool, len(entries))
+	projects := []Project{}
+	var warnings []string
+	for _, entry := range entries {
+		if !isProjectDir(entry) {
+			continue
+		}
+		name := entry.Name()
+		seen[name] = true
+
+		info, err := os.Stat(filepath.Join(baseDir, name, "project.yaml"))
+		if errors.Is(err, fs.ErrNotExist) {
+			warnings = append(warnings, fmt.Sprintf("skipping %s: no project.yaml", name))
+			continue
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipping %s: %v", name, err))
+			continue
+		}
+
+		cached, ok := idx.Entries[name]
+		if ok && cached.ModTime == info.ModTime().UnixNano() && cached.Size == info.Size() {
+			projects = append(projects, cached.Project)
+			continue
+		}
+
+		p, err := ReadProjectFile(baseDir, name)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("skipping %s: %v", name, err))
+			continue
+		}
+		idx.Entries[name] = indexEntry{ModTime: info.ModTime().UnixNano(), Size: info.Size(), Project: p}
+		changed = true
+		projects = append(projects, p)
+	}
+
+	for name := range idx.Entries {
+		if !seen[name] {
+			delete(idx.Entries, name)
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := saveIndex(baseDir, idx); err != nil {
+			warnings = append(warnings, fmt.Sprintf("could not update project index: %v", err))
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, warnings, nil
+}
+
+// Reindex throws away the cached index and rebuilds it from every
+// project.yaml in baseDir.
+func Reindex(baseDir string) (int, []string, error) {
+	projects, warnings, err := scanProjects(baseDir, true)
+	return len(projects), warnings, err
+}
+
+// SearchProjects keeps the projects whose ID, name or tags contain query,
+// ignoring case.
+func SearchProjects(projects []Project, query string) []Project {
+	q := strings.ToUpper(strings.TrimSpace(query))
+	if q == "" {
+		return projects
+	}
+
+	var found []Project
+	for _, p := range projects {
+		if strings.Contains(strings.ToUpper(p.ID), q) ||
+			strings.Contains(strings.ToUpper(p.Name), q) ||
+			strings.Contains(strings.ToUpper(strings.Join(p.Tags, "\n")), q) {
+			found = append(found, p)
+		}
+	}
+	return found
+}
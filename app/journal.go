@@ -0,0 +1,205 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// Every mutation projman makes to a project is appended as one JSON line to
+// <project>/Logs/projman-journal.jsonl.
+const (
+	journalDir  = "Logs"
+	journalFile = "projman-journal.jsonl"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionStatus  = "status"
+	ActionArchive = "archive"
+	ActionRestore = "restore"
+	ActionTags    = "tags"
+	ActionMigrate = "migrate"
+	ActionPreset  = "preset"
+	ActionImport  = "import"
+)
+
+type JournalEntry struct {
+	Time    string        `json:"time"`
+	User    string        `json:"user"`
+	Action  string        `json:"action"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	Note    string        `json:"note,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+func JournalPath(projectPath string) string {
+	return filepath.Join(projectPath, journalDir, journalFile)
+}
+
+// AppendJournal stamps the entry with the current time and user if unset
+// and appends it to the project's journal.
+func AppendJournal(projectPath string, e JournalEntry) error {
+	if e.Time == "" {
+		e.Time = Timestamp()
+	}
+	if e.User == "" {
+		e.User = CurrentUser()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	path := JournalPath(projectPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return f.Close()
+}
+
+// ReadJournal returns a project's journal oldest first. A project without
+// a journal yet has no entries.
+func ReadJournal(projectPath string) ([]JournalEntry, error) {
+	f, err := os.Open(JournalPath(projectPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return entries, fmt.Errorf("%s:%d: %w", JournalPath(projectPath), n, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// WriteJournal renders journal entries as a table or as JSON lines.
+func WriteJournal(w io.Writer, entries []JournalEntry, format Format) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatTable:
+		if len(entries) == 0 {
+			_, err := fmt.Fprintln(w, "📭 No journal entries yet.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Time\tUser\tAction\tChange")
+		fmt.Fprintln(tw, "----\t----\t------\t------")
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Time, e.User, e.Action, e.Summary())
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("journal output supports table and json, not %q", format)
+}
+
+// Summary describes an entry's changes on one line.
+func (e JournalEntry) Summary() string {
+	parts := make([]string, 0, len(e.Changes)+1)
+	for _, c := range e.Changes {
+		parts = append(parts, fmt.Sprintf("%s: %q → %q", c.Field, c.Old, c.New))
+	}
+	if e.Note != "" {
+		parts = append(parts, e.Note)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// diffProjects lists the fields that differ between two versions of a project.
+func diffProjects(old, new Project) []FieldChange {
+	var changes []FieldChange
+	add := func(field, o, n string) {
+		if o != n {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	add("name", old.Name, new.Name)
+	add("description", old.Description, new.Description)
+	add("tags", strings.Join(old.Tags, ", "), strings.Join(new.Tags, ", "))
+	for _, name := range fieldNames(mergeMaps(old.Fields, new.Fields)) {
+		add("fields."+name, old.Fields[name], new.Fields[name])
+	}
+	return changes
+}
+
+func journalStatus(projectPath, old, new string) error {
+	return AppendJournal(projectPath, JournalEntry{
+		Action:  ActionStatus,
+		Changes: []FieldChange{{Field: "status", Old: old, New: new}},
+	})
+}
+
+// FindProjectRoot walks up from path to the nearest folder holding a
+// project.yaml.
+func FindProjectRoot(path string) (string, bool) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "project.yaml")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func mergeMaps(a, b map[string]string) map[string]string {
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, b)
+	return merged
+}
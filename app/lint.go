@@ -0,0 +1,229 @@
+package app
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TagRulesFile holds a project's tag rules. It is looked up in the root of
+// the project holding the linted file, then in the base directory's Config
+// folder.
+const TagRulesFile = "tag-rules.yaml"
+
+// TagRules are what "projman tags lint" checks tags against. Pattern may
+// name the function and product codes with (?P<function>...) and
+// (?P<product>...) groups, which are then checked against Functions and
+// Products when those are set.
+type TagRules struct {
+	Pattern   string   `yaml:"pattern,omitempty"`
+	Functions []string `yaml:"functions,omitempty"`
+	Products  []string `yaml:"products,omitempty"`
+	Unique    bool     `yaml:"unique"`
+	// Classes are the letter codes allowed in reference designations.
+	Classes ClassTable `yaml:"classes,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// TagIssue is one problem found by LintTags.
+type TagIssue struct {
+	Line    int
+	Tag     string
+	Message string
+}
+
+// LintReport lists the problems found in a tag file.
+type LintReport struct {
+	Path   string
+	Tags   int
+	Issues []TagIssue
+}
+
+// LoadTagRules reads the rules that apply to path and returns them with the
+// file they came from. Without a rules file only uniqueness is checked.
+func LoadTagRules(path string) (TagRules, string, error) {
+	var candidates []string
+	if root, ok := FindProjectRoot(path); ok {
+		candidates = append(candidates, filepath.Join(root, TagRulesFile))
+	}
+	candidates = append(candidates, filepath.Join(config.BaseDir, ConfigDir, TagRulesFile))
+
+	for _, c := range candidates {
+		rules, err := ReadTagRules(c)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return rules, c, err
+	}
+	rules := TagRules{Unique: true}
+	return rules, "", rules.compile()
+}
+
+// ReadTagRules reads a rules file. unique defaults to true.
+func ReadTagRules(path string) (TagRules, error) {
+	rules := TagRules{Unique: true}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rules, err
+	}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("read tag rules %s: %w", path, err)
+	}
+	if err := rules.compile(); err != nil {
+		return rules, fmt.Errorf("tag rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func (r *TagRules) compile() error {
+	if r.Pattern == "" {
+		if len(r.Functions) > 0 || len(r.Products) > 0 {
+			return fmt.Errorf("functions and products need a pattern with (?P<function>...) and (?P<product>...) groups")
+		}
+		return nil
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("bad pattern: %w", err)
+	}
+	for group, codes := range map[string][]string{"function": r.Functions, "product": r.Products} {
+		if len(codes) > 0 && re.SubexpIndex(group) < 0 {
+			return fmt.Errorf("%s codes are listed but the pattern has no (?P<%s>...) group", group, group)
+		}
+	}
+	r.re = re
+	return nil
+}
+
+func (r TagRules) check(tag string) []string {
+	if r.re == nil {
+		return nil
+	}
+	m := r.re.FindStringSubmatch(tag)
+	if m == nil {
+		return []string{fmt.Sprintf("does not match %s", r.Pattern)}
+	}
+	var problems []string
+	for group, codes := range map[string][]string{"function": r.Functions, "product": r.Products} {
+		if i := r.re.SubexpIndex(group); i >= 0 && len(codes) > 0 && !slices.Contains(codes, m[i]) {
+			problems = append(problems, fmt.Sprintf("unknown %s code %q", group, m[i]))
+		}
+	}
+	slices.Sort(problems)
+	return problems
+}
+
+// LintTags checks every tag in a YAML or CSV tag file against rules. In
+// YAML, tags are the values of any id or tag key and the keys under
+// Equipment in the nested format; in CSV, the tag or id column.
+func LintTags(path string, rules TagRules) (LintReport, error) {
+	report := LintReport{Path: path}
+	var tags []TagIssue
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		tags, err = readCSVTags(path)
+	case ".yaml", ".yml":
+		tags, err = readYAMLTags(path)
+	default:
+		return report, fmt.Errorf("%s: can only lint .yaml, .yml and .csv files", path)
+	}
+	if err != nil {
+		return report, err
+	}
+	report.Tags = len(tags)
+
+	firstSeen := map[string]int{}
+	for _, t := range tags {
+		for _, problem := range rules.check(t.Tag) {
+			report.Issues = append(report.Issues, TagIssue{Line: t.Line, Tag: t.Tag, Message: problem})
+		}
+		if first, dup := firstSeen[t.Tag]; dup && rules.Unique {
+			report.Issues = append(report.Issues, TagIssue{Line: t.Line, Tag: t.Tag, Message: fmt.Sprintf("duplicate of line %d", first)})
+		} else if !dup {
+			firstSeen[t.Tag] = t.Line
+		}
+	}
+	return report, nil
+}
+
+func readCSVTags(path string) ([]TagIssue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv %s: %w", path, err)
+	}
+	col := slices.IndexFunc(header, func(h string) bool { return TagColumn(h) == "tag" })
+	if col < 0 {
+		col = slices.IndexFunc(header, func(h string) bool { return TagColumn(h) == "id" })
+	}
+	if col < 0 {
+		return nil, fmt.Errorf("%s has no tag or id column", path)
+	}
+
+	var tags []TagIssue
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return tags, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read csv %s: %w", path, err)
+		}
+		if col < len(row) && strings.TrimSpace(row[col]) != "" {
+			line, _ := reader.FieldPos(col)
+			tags = append(tags, TagIssue{Line: line, Tag: strings.TrimSpace(row[col])})
+		}
+	}
+}
+
+func readYAMLTags(path string) ([]TagIssue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("read yaml %s: %w", path, err)
+	}
+
+	var tags []TagIssue
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				if key := strings.ToLower(k.Value); (key == "id" || key == "tag") && v.Kind == yaml.ScalarNode {
+					tags = append(tags, TagIssue{Line: v.Line, Tag: v.Value})
+				}
+				if k.Value == treeEquipment && v.Kind == yaml.MappingNode {
+					for j := 0; j+1 < len(v.Content); j += 2 {
+						tags = append(tags, TagIssue{Line: v.Content[j].Line, Tag: v.Content[j].Value})
+					}
+				}
+			}
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(&root)
+	return tags, nil
+}
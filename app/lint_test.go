@@ -0,0 +1,51 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLintTagsNested(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.yaml")
+	writeFile(t, path, `Line4:
+  Catalyst:
+    SystemCode: CAT
+    Equipment:
+      CAT-PMP-01:
+        Name: Pump 1
+        Subcat: PMP
+        Motor:
+          Tag: CAT-MTR-01
+          Subcat: MTR
+      cat-pmp-02:
+        Name: Pump 2
+        Subcat: PMP
+        Motor:
+          Tag: CAT-MTR-01
+          Subcat: MTR
+`)
+	rules := TagRules{Pattern: `^[A-Z]+-[A-Z]+-\d{2}$`, Unique: true}
+	if err := rules.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := LintTags(path, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Tags != 4 {
+		t.Errorf("counted %d tags, want 4", report.Tags)
+	}
+	want := []TagIssue{
+		{Line: 11, Tag: "cat-pmp-02", Message: `does not match ^[A-Z]+-[A-Z]+-\d{2}$`},
+		{Line: 15, Tag: "CAT-MTR-01", Message: "duplicate of line 9"},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("issues = %+v, want %+v", report.Issues, want)
+	}
+	for i := range want {
+		if report.Issues[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, report.Issues[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Lock files are plain files created with O_EXCL, which works on network
+// shares where flock doesn't. A lock older than lockStale is assumed to be
+// left over from a crashed process and is taken over.
+var (
+	lockWait  = 10 * time.Second
+	lockStale = 2 * time.Minute
+)
+
+// acquireLock creates the lock file at path, waiting up to lockWait for
+// another holder to let go. The returned func releases it, if it is still
+// ours.
+func acquireLock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	token := fmt.Sprintf("%s@%s pid %d %x\n", CurrentUser(), host, os.Getpid(), time.Now().UnixNano())
+	release := func() {
+		if owner, err := os.ReadFile(path); err == nil && string(owner) == token {
+			os.Remove(path)
+		}
+	}
+
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(token)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return release, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if takeOverLock(path, token) {
+			return release, nil
+		}
+		if time.Now().After(deadline) {
+			owner, _ := os.ReadFile(path)
+			return nil, fmt.Errorf("%w: %s held by %s", ErrLocked, path, strings.TrimSpace(string(owner)))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// takeOverLock replaces a stale lock at path with one holding token. The
+// new lock is renamed over the old one so there is never a moment without
+// a lock, and it is read back after a pause in case another process took
+// the same stale lock over at the same time.
+func takeOverLock(path, token string) bool {
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) <= lockStale {
+		return false
+	}
+	stale, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return false
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	_, err = f.WriteString(token)
+	if cerr := f.Close(); err != nil || cerr != nil {
+		return false
+	}
+	// Only replace the lock we judged stale, not one just taken by someone else.
+	if current, err := os.ReadFile(path); err != nil || string(current) != string(stale) {
+		return false
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return false
+	}
+	time.Sleep(50 * time.Millisecond)
+	owner, err := os.ReadFile(path)
+	return err == nil && string(owner) == token
+}
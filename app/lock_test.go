@@ -0,0 +1,108 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireLockExcludes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	var holders, most atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireLock(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := holders.Add(1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			time.Sleep(2 * time.Millisecond)
+			holders.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if most.Load() != 1 {
+		t.Errorf("%d holders at once", most.Load())
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock left behind: %v", err)
+	}
+}
+
+func TestAcquireLockTimesOut(t *testing.T) {
+	saved := lockWait
+	t.Cleanup(func() { lockWait = saved })
+	lockWait = 100 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "test.lock")
+	release, err := acquireLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if _, err := acquireLock(path); !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), "pid") {
+		t.Errorf("second acquire: err = %v, want ErrLocked naming the holder", err)
+	}
+}
+
+func TestStaleLockTakeover(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.lock")
+	writeFile(t, path, "crashed@host pid 1\n")
+	old := time.Now().Add(-2 * lockStale)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	var holders, most atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireLock(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := holders.Add(1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			time.Sleep(2 * time.Millisecond)
+			holders.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if most.Load() != 1 {
+		t.Errorf("%d holders at once after taking over a stale lock", most.Load())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("left behind %v", entries)
+	}
+}
+
+func TestReleaseKeepsOthersLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	release, err := acquireLock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "someone@else pid 2\n")
+	release()
+	if data, err := os.ReadFile(path); err != nil || string(data) != "someone@else pid 2\n" {
+		t.Errorf("release removed or changed another holder's lock: %q, %v", data, err)
+	}
+}
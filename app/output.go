@@ -0,0 +1,269 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+)
+
+var formats = []Format{FormatTable, FormatJSON, FormatYAML, FormatCSV, FormatTSV}
+
+// projectFields maps each selectable column to its value on a Project.
+var projectFields = map[string]func(p Project) any{
+	"id":          func(p Project) any { return p.ID },
+	"name":        func(p Project) any { return p.Name },
+	"status":      func(p Project) any { return p.Status },
+	"tags":        func(p Project) any { return append([]string{}, p.Tags...) },
+	"created_at":  func(p Project) any { return p.CreatedAt },
+	"updated_at":  func(p Project) any { return p.UpdatedAt },
+	"description": func(p Project) any { return p.Description },
+	"path":        func(p Project) any { return p.Path },
+	"preset":      func(p Project) any { return p.Preset },
+	"fields":      func(p Project) any { return p.Fields },
+	"workspace":   func(p Project) any { return p.Workspace },
+}
+
+var (
+	// ProjectFields lists every column in display order.
+	ProjectFields = []string{"id", "name", "status", "tags", "created_at", "updated_at", "description", "path", "preset", "fields"}
+	// ListFields are the columns `list` shows in a table when none are selected.
+	ListFields = []string{"id", "name", "status", "created_at"}
+	// WorkspaceField is only filled in when listing across workspaces, so
+	// it is left out of ProjectFields.
+	WorkspaceField = "workspace"
+)
+
+var fieldLabels = map[string]string{
+	"id":          "ID",
+	"name":        "Name",
+	"status":      "Status",
+	"tags":        "Tags",
+	"created_at":  "Created At",
+	"updated_at":  "Updated At",
+	"description": "Description",
+	"path":        "Path",
+	"preset":      "Preset",
+	"fields":      "Fields",
+	"workspace":   "Workspace",
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %q (want one of %s)", s, joinFormats())
+}
+
+func joinFormats() string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseFields splits a comma-separated column list and checks every name.
+// An empty string returns nil so renderers can apply their own defaults.
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" {
+			continue
+		}
+		if _, ok := projectFields[f]; !ok {
+			return nil, fmt.Errorf("unknown field %q (want any of %s)", f, strings.Join(append(ProjectFields, WorkspaceField), ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// WriteProjects renders a project listing in the given format.
+func WriteProjects(w io.Writer, projects []Project, format Format, fields []string) error {
+	if len(fields) == 0 {
+		fields = ProjectFields
+		if format == FormatTable {
+			fields = ListFields
+		}
+	}
+
+	switch format {
+	case FormatJSON, FormatYAML:
+		records := make([]record, len(projects))
+		for i, p := range projects {
+			records[i] = newRecord(p, fields)
+		}
+		return encode(w, format, records)
+	case FormatCSV, FormatTSV:
+		return writeDelimited(w, projects, fields, format == FormatTSV)
+	case FormatTable:
+		if len(projects) == 0 {
+			_, err := fmt.Fprintln(w, "📭 No valid projects found.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := make([]string, len(fields))
+		rule := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = fieldLabels[f]
+			rule[i] = strings.Repeat("-", len(header[i]))
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		fmt.Fprintln(tw, strings.Join(rule, "\t"))
+		for _, p := range projects {
+			row := make([]string, len(fields))
+			for i, f := range fields {
+				row[i] = fieldString(p, f)
+			}
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// WriteProject renders a single project in the given format.
+func WriteProject(w io.Writer, p Project, format Format, fields []string) error {
+	if len(fields) == 0 {
+		fields = ProjectFields
+	}
+
+	switch format {
+	case FormatJSON, FormatYAML:
+		return encode(w, format, newRecord(p, fields))
+	case FormatCSV, FormatTSV:
+		return writeDelimited(w, []Project{p}, fields, format == FormatTSV)
+	case FormatTable:
+		var b strings.Builder
+		b.WriteString("󱖫 Project Status\n")
+		b.WriteString(strings.Repeat("=", 50) + "\n")
+		for _, f := range fields {
+			if f != "fields" {
+				fmt.Fprintf(&b, "%-13s%s\n", fieldLabels[f]+":", fieldString(p, f))
+				continue
+			}
+			for _, name := range fieldNames(p.Fields) {
+				fmt.Fprintf(&b, "%-13s%s\n", name+":", p.Fields[name])
+			}
+		}
+		b.WriteString(strings.Repeat("=", 50) + "\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func fieldString(p Project, field string) string {
+	switch v := projectFields[field](p).(type) {
+	case []string:
+		return strings.Join(v, ", ")
+	case map[string]string:
+		pairs := make([]string, 0, len(v))
+		for _, name := range fieldNames(v) {
+			pairs = append(pairs, name+"="+v[name])
+		}
+		return strings.Join(pairs, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeDelimited(w io.Writer, projects []Project, fields []string, tabs bool) error {
+	cw := csv.NewWriter(w)
+	if tabs {
+		cw.Comma = '\t'
+	}
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	for _, p := range projects {
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = fieldString(p, f)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func encode(w io.Writer, format Format, v any) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+	return enc.Encode(v)
+}
+
+// record keeps the selected fields in the order they were asked for,
+// which plain maps would lose in both JSON and YAML.
+type record []recordField
+
+type recordField struct {
+	key   string
+	value any
+}
+
+func newRecord(p Project, fields []string) record {
+	r := make(record, len(fields))
+	for i, f := range fields {
+		r[i] = recordField{f, projectFields[f](p)}
+	}
+	return r
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range r {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.key)
+		val, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (r record) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range r {
+		var val yaml.Node
+		if err := val.Encode(f.value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.key}, &val)
+	}
+	return node, nil
+}
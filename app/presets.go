@@ -1,45 +1,302 @@
 package app
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultPresetName is always available, even without a preset file, so a
+// fresh base directory can still create projects.
+const DefaultPresetName = "default"
+
+// Preset describes the layout of a new project. Extends names other presets
+// to build on; LoadPreset returns the merged result.
 type Preset struct {
+	Name    string            `yaml:"name"`
+	Extends []string          `yaml:"extends,omitempty"`
+	Folders []Folder          `yaml:"folders"`
+	Files   []PresetFile      `yaml:"files,omitempty"`
+	Tags    []string          `yaml:"tags,omitempty"`
+	Vars    map[string]string `yaml:"vars,omitempty"`
+}
+
+// Folder is one directory in a preset tree. In YAML a folder without
+// children can be written as a plain string:
+//
+//	folders:
+//	  - Docs
+//	  - name: Planning
+//	    folders: [Schedules, Budgets]
+type Folder struct {
 	Name    string   `yaml:"name"`
-	Folders []string `yaml:"folders"`
+	Folders []Folder `yaml:"folders,omitempty"`
 }
 
-var (
-	presetsDirectory = config.BaseDir + "Config/Presets"
-	presets          = []string{}
-)
+func (f *Folder) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Name = node.Value
+		return nil
+	}
+	type plain Folder
+	return node.Decode((*plain)(f))
+}
 
-func GetAvailablePresets() []string {
-	files, err := os.ReadDir(presetsDirectory)
-	if err != nil {
-		log.Printf("failed to read preset directory: %s", err)
+func (f Folder) MarshalYAML() (any, error) {
+	if len(f.Folders) == 0 {
+		return f.Name, nil
 	}
-	presetFiles := []string{}
+	type plain Folder
+	return plain(f), nil
+}
+
+var defaultPreset = Preset{
+	Name: DefaultPresetName,
+	Folders: []Folder{
+		{Name: "Docs"}, {Name: "Planning"}, {Name: "Logs"}, {Name: "Exports"},
+	},
+}
+
+func PresetsDir(baseDir string) string {
+	return filepath.Join(baseDir, ConfigDir, "Presets")
+}
+
+// GetAvailablePresets lists the preset names in baseDir/Config/Presets plus
+// the built-in default. If Config.FolderPresets is set, only those presets
+// are offered, in that order.
+func GetAvailablePresets(baseDir string) ([]string, error) {
+	files, err := os.ReadDir(PresetsDir(baseDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read preset directory: %w", err)
+	}
+
+	presets := []string{DefaultPresetName}
 	for _, file := range files {
-		presetFiles = append(presetFiles, strings.Split(file.Name(), ".")[0])
-		if strings.Contains(file.Name(), ".yaml") {
-			presets = append(presets, file.Name())
+		name, ok := presetName(file.Name())
+		if ok && !file.IsDir() && !slices.Contains(presets, name) {
+			presets = append(presets, name)
+		}
+	}
+	slices.Sort(presets[1:])
+
+	if len(config.FolderPresets) == 0 {
+		return presets, nil
+	}
+	var offered []string
+	for _, name := range config.FolderPresets {
+		if slices.Contains(presets, name) {
+			offered = append(offered, name)
 		}
 	}
+	return offered, nil
 }
 
-func LoadPreset(name string) (Preset, error) {
+func presetName(file string) (string, bool) {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if strings.HasSuffix(file, ext) {
+			return strings.TrimSuffix(file, ext), true
+		}
+	}
+	return "", false
+}
+
+// LoadPreset reads baseDir/Config/Presets/<name>.yaml and resolves its
+// extends chain. An empty name means Config.DefaultPreset, and the default
+// preset falls back to the built-in folder layout when no file overrides it.
+func LoadPreset(baseDir, name string) (Preset, error) {
+	if name == "" {
+		name = config.DefaultPreset
+	}
+	if name == "" {
+		name = DefaultPresetName
+	}
+	return resolvePreset(baseDir, name, nil)
+}
+
+// resolvePreset loads name and merges it over the presets it extends, in
+// order, so later ones win. stack holds the chain being resolved and is
+// how cycles are caught.
+func resolvePreset(baseDir, name string, stack []string) (Preset, error) {
+	if slices.Contains(stack, name) {
+		return Preset{}, fmt.Errorf("%w: extends cycle %s", ErrInvalidPreset, strings.Join(append(stack, name), " → "))
+	}
+	stack = append(stack, name)
+
+	p, err := readPreset(baseDir, name)
+	if err != nil {
+		return p, err
+	}
+
+	var resolved Preset
+	for _, parent := range p.Extends {
+		base, err := resolvePreset(baseDir, parent, stack)
+		if err != nil {
+			return p, err
+		}
+		resolved = mergePresets(resolved, base)
+	}
+	resolved = mergePresets(resolved, p)
+	resolved.Name = name
+	resolved.Extends = nil
+	return resolved, nil
+}
+
+// readPreset loads a single preset file without following extends.
+func readPreset(baseDir, name string) (Preset, error) {
 	var p Preset
-	path := filepath.Join(presetsDirectory, name+".yaml")
-	data, err := os.ReadFile(path)
+	data, path, err := readPresetFile(baseDir, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		if name == DefaultPresetName {
+			return defaultPreset, nil
+		}
+		return p, fmt.Errorf("%w: %s", ErrPresetNotFound, name)
+	}
 	if err != nil {
 		return p, err
 	}
-	err = yaml.Unmarshal(data, &p)
-	return p, err
+
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("%w: %s: %v", ErrInvalidPreset, path, err)
+	}
+	p.Name = name
+	if err := validateFolders(p.Folders); err != nil {
+		return p, fmt.Errorf("%w: %s: %v", ErrInvalidPreset, path, err)
+	}
+	if err := resolveFiles(p.Files, filepath.Join(PresetsDir(baseDir), name)); err != nil {
+		return p, fmt.Errorf("%w: %s: %v", ErrInvalidPreset, path, err)
+	}
+	for i := range p.Files {
+		p.Files[i].From = name
+	}
+	return p, nil
+}
+
+// mergePresets layers over on top of base: folders merge by name at every
+// level, files replace earlier ones with the same path, tags are unioned
+// and vars are overridden key by key.
+func mergePresets(base, over Preset) Preset {
+	merged := Preset{
+		Name:    over.Name,
+		Folders: mergeFolders(base.Folders, over.Folders),
+		Tags:    addTags(base.Tags, over.Tags),
+	}
+
+	merged.Files = append(merged.Files, base.Files...)
+	for _, f := range over.Files {
+		i := slices.IndexFunc(merged.Files, func(e PresetFile) bool { return e.Path == f.Path })
+		if i >= 0 {
+			merged.Files[i] = f
+		} else {
+			merged.Files = append(merged.Files, f)
+		}
+	}
+
+	if len(base.Vars)+len(over.Vars) > 0 {
+		merged.Vars = map[string]string{}
+		for k, v := range base.Vars {
+			merged.Vars[k] = v
+		}
+		for k, v := range over.Vars {
+			merged.Vars[k] = v
+		}
+	}
+	return merged
+}
+
+func mergeFolders(base, over []Folder) []Folder {
+	merged := append([]Folder{}, base...)
+	for _, f := range over {
+		i := slices.IndexFunc(merged, func(e Folder) bool { return e.Name == f.Name })
+		if i >= 0 {
+			merged[i] = Folder{Name: f.Name, Folders: mergeFolders(merged[i].Folders, f.Folders)}
+		} else {
+			merged = append(merged, f)
+		}
+	}
+	return merged
+}
+
+func readPresetFile(baseDir, name string) ([]byte, string, error) {
+	var lastErr error
+	for _, ext := range []string{".yaml", ".yml"} {
+		path := filepath.Join(PresetsDir(baseDir), name+ext)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, path, nil
+		}
+		lastErr = err
+		if !errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+	}
+	return nil, "", lastErr
+}
+
+// validateFolders keeps preset folders inside the project: no empty names,
+// no absolute paths and no "..".
+func validateFolders(folders []Folder) error {
+	for _, f := range folders {
+		name := strings.TrimSpace(f.Name)
+		if name == "" {
+			return fmt.Errorf("folder with an empty name")
+		}
+		if !insideFolder(name) {
+			return fmt.Errorf("folder %q must stay inside the project", f.Name)
+		}
+		if err := validateFolders(f.Folders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildFolders creates a preset's folder tree under root, rendering any
+// template in the folder names.
+func buildFolders(root string, folders []Folder, data TemplateData) error {
+	for _, f := range folders {
+		name, err := renderString(f.Name, f.Name, data)
+		if err != nil {
+			return fmt.Errorf("render folder %s: %w", f.Name, err)
+		}
+		if !insideFolder(name) {
+			return fmt.Errorf("folder %q renders outside the project", f.Name)
+		}
+		dir := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create subdirectory %s: %w", dir, err)
+		}
+		if err := buildFolders(dir, f.Folders, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderPaths lists every folder in a preset tree as a rendered path
+// relative to the project, parents before children.
+func folderPaths(folders []Folder, data TemplateData, parent string) ([]string, error) {
+	var paths []string
+	for _, f := range folders {
+		name, err := renderString(f.Name, f.Name, data)
+		if err != nil {
+			return nil, fmt.Errorf("render folder %s: %w", f.Name, err)
+		}
+		if !insideFolder(name) {
+			return nil, fmt.Errorf("folder %q renders outside the project", f.Name)
+		}
+		path := filepath.ToSlash(filepath.Join(parent, name))
+		children, err := folderPaths(f.Folders, data, path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		paths = append(paths, children...)
+	}
+	return paths, nil
 }
@@ -1,11 +1,16 @@
 package app
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,18 +19,52 @@ import (
 
 // app project metadata
 type Project struct {
+	SchemaVersion int `yaml:"schema_version"`
+
 	ID          string   `yaml:"id"`
 	Name        string   `yaml:"name"`
 	Status      string   `yaml:"status"`
 	Tags        []string `yaml:"tags"`
 	CreatedAt   string   `yaml:"created_at"`
+	UpdatedAt   string   `yaml:"updated_at,omitempty"`
 	Description string   `yaml:"description"`
 	Path        string   `yaml:"path"`
+	Preset      string   `yaml:"preset,omitempty"`
+
+	// Fields holds the values of the custom fields configured in
+	// PROJMAN_FIELDS, in their canonical text form.
+	Fields map[string]string `yaml:"fields,omitempty"`
+
+	StatusHistory []StatusChange `yaml:"status_history,omitempty"`
+
+	// Workspace is filled in by ListAllWorkspaces and never stored.
+	Workspace string `yaml:"-" json:"-"`
 }
 
 // Struct for CLI/TUI parameters
 type Params struct {
-	ID, Name, Description, Status, Tags string
+	ID, Name, Description, Status, Tags, Preset string
+
+	// Vars are extra template variables for the preset's files.
+	Vars map[string]string
+	// Fields sets custom field values; defaults fill in the rest.
+	Fields map[string]string
+	// AutoID ignores ID and allocates the next one from
+	// PROJMAN_PROJECT_ID_PATTERN.
+	AutoID bool
+}
+
+// Changes to apply to an existing project; nil fields are left untouched.
+// Tags replaces the whole list and is applied before AddTags and RemoveTags.
+type Changes struct {
+	Name        *string
+	Description *string
+	Status      *string
+	Tags        *[]string
+	AddTags     []string
+	RemoveTags  []string
+	// Fields sets custom field values; an empty value removes the field.
+	Fields map[string]string
 }
 
 func Timestamp() string {
@@ -50,19 +89,36 @@ func CleanTags(tags string) []string {
 	return clean
 }
 
+// isProjectDir skips the folders projman keeps in the base directory
+// itself: Archive, Config and hidden ones such as .projman.
+func isProjectDir(entry fs.DirEntry) bool {
+	name := entry.Name()
+	return entry.IsDir() && name != ArchiveDir && name != ConfigDir && !strings.HasPrefix(name, ".")
+}
+
 func ReadProjectFile(baseDir, id string) (Project, error) {
 	var p Project
 	id = ValidateID(id)
 	path := filepath.Join(baseDir, id, "project.yaml")
 	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, fmt.Errorf("%w: %s", ErrProjectNotFound, id)
+	}
 	if err != nil {
 		return p, err
 	}
-	err = yaml.Unmarshal(data, &p)
-	return p, err
+	p, _, _, err = decodeProject(data)
+	if errors.Is(err, ErrSchemaTooNew) {
+		return p, fmt.Errorf("%s: %w", path, err)
+	}
+	if err != nil {
+		return p, fmt.Errorf("%w: %s: %v", ErrInvalidProject, path, err)
+	}
+	return p, nil
 }
 
 func WriteProjectFile(p Project) error {
+	p.SchemaVersion = CurrentSchemaVersion
 	data, err := yaml.Marshal(&p)
 	if err != nil {
 		return err
@@ -72,96 +128,196 @@ func WriteProjectFile(p Project) error {
 
 // 🔓 Public API
 
-func GetDefaultBaseDir() string {
+func GetDefaultBaseDir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		log.Fatalf("Failed to determine home directory: %v", err)
+		return "", fmt.Errorf("determine home directory: %w", err)
 	}
-	return filepath.Join(home, "Projects")
+	return filepath.Join(home, "Projects"), nil
 }
 
-func CreateProject(baseDir string, p Params) {
-	id := ValidateID(p.ID)
-	path := filepath.Join(baseDir, id)
-
-	if _, err := os.Stat(path); !os.IsNotExist(err) {
-		log.Fatalf("Project %s already exists", id)
+func OpenFolder(path string) error {
+	openCmd := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		openCmd = "open"
+	} else if runtime.GOOS == "windows" {
+		openCmd = "explorer"
 	}
+	return exec.Command(openCmd, path).Start()
+}
 
-	if err := os.MkdirAll(path, 0755); err != nil {
-		log.Fatalf("Failed to create project directory: %v", err)
+func CreateProject(baseDir string, p Params) (Project, error) {
+	fields, err := applyFields(nil, p.Fields, true)
+	if err != nil {
+		return Project{}, err
+	}
+	if p.AutoID {
+		// Hold the lock until the folder exists so the ID stays ours.
+		release, err := acquireLock(idLockPath(baseDir))
+		if err != nil {
+			return Project{}, err
+		}
+		defer release()
+		if p.ID, err = NextProjectID(baseDir, fields); err != nil {
+			return Project{}, err
+		}
 	}
 
-	defaultDirs := []string{
-		"Docs", "Planning", "Logs", "Exports",
+	id := ValidateID(p.ID)
+	if id == "" {
+		return Project{}, fmt.Errorf("%w: %q", ErrInvalidID, p.ID)
 	}
-	for _, d := range defaultDirs {
-		sub := filepath.Join(path, d)
-		if err := os.MkdirAll(sub, 0755); err != nil {
-			log.Fatalf("Failed to create subdirectory %s: %v", sub, err)
-		}
+	path := filepath.Join(baseDir, id)
+
+	preset, err := LoadPreset(baseDir, p.Preset)
+	if err != nil {
+		return Project{}, err
 	}
 
 	proj := Project{
 		ID:          id,
 		Name:        p.Name,
 		Description: p.Description,
-		Status:      p.Status,
-		Tags:        CleanTags(p.Tags),
+		Tags:        addTags(preset.Tags, CleanTags(p.Tags)),
 		CreatedAt:   Timestamp(),
 		Path:        path,
+		Preset:      preset.Name,
+		Fields:      fields,
+	}
+	status := p.Status
+	if status == "" {
+		status = config.DefaultStatus
+	}
+	if _, err := changeStatus(&proj, status); err != nil {
+		return Project{}, err
 	}
 
-	if err := WriteProjectFile(proj); err != nil {
-		log.Fatalf("Failed to write project file: %v", err)
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return Project{}, fmt.Errorf("create base directory: %w", err)
+	}
+	// Mkdir fails if the folder exists, so whoever creates it first owns
+	// the ID, whether it was typed in or allocated.
+	if err := os.Mkdir(path, 0755); errors.Is(err, fs.ErrExist) {
+		return Project{}, fmt.Errorf("%w: %s", ErrProjectExists, id)
+	} else if err != nil {
+		return Project{}, fmt.Errorf("create project directory: %w", err)
+	}
+	if err := scaffoldProject(proj, preset, newTemplateData(proj, preset, p.Vars)); err != nil {
+		// Don't leave a half-built folder that blocks a retry with the same ID.
+		os.RemoveAll(path)
+		return Project{}, err
 	}
 
-	fmt.Printf("✅ Created project %s at %s\n", id, path)
+	changes := append([]FieldChange{{Field: "id", New: id}}, diffProjects(Project{}, proj)...)
+	changes = append(changes, FieldChange{Field: "status", New: proj.Status}, FieldChange{Field: "preset", New: proj.Preset})
+	err = AppendJournal(path, JournalEntry{Action: ActionCreate, Changes: changes})
+	return proj, err
 }
 
-func ListProjects(baseDir string) {
-	entries, err := os.ReadDir(baseDir)
+// scaffoldProject lays out a new project folder from its preset, renders
+// the preset's starter files and writes project.yaml.
+func scaffoldProject(proj Project, preset Preset, data TemplateData) error {
+	if err := buildFolders(proj.Path, preset.Folders, data); err != nil {
+		return err
+	}
+	if _, err := renderFiles(proj.Path, preset.Files, data); err != nil {
+		return err
+	}
+	if err := WriteProjectFile(proj); err != nil {
+		return fmt.Errorf("write project file: %w", err)
+	}
+	return nil
+}
+
+func UpdateProject(baseDir, id string, c Changes) (Project, error) {
+	p, err := ReadProjectFile(baseDir, id)
 	if err != nil {
-		log.Fatalf("Failed to read base directory: %v", err)
+		return p, err
 	}
 
-	fmt.Printf("%-12s %-25s %-10s %-20s\n", "ID", "Name", "Status", "Created")
-	fmt.Println(strings.Repeat("-", 70))
-	found := false
-	for _, entry := range entries {
-		if !entry.IsDir() || entry.Name() == "Archive" || strings.HasPrefix(entry.Name(), ".") {
-			continue
+	before := p
+	changed := false
+	set := func(field *string, value *string) {
+		if value != nil && *field != *value {
+			*field = *value
+			changed = true
+		}
+	}
+	set(&p.Name, c.Name)
+	set(&p.Description, c.Description)
+	if c.Status != nil {
+		moved, err := changeStatus(&p, *c.Status)
+		if err != nil {
+			return p, err
 		}
+		changed = changed || moved
+	}
+
+	tags := p.Tags
+	if c.Tags != nil {
+		tags = *c.Tags
+	}
+	tags = addTags(tags, c.AddTags)
+	tags = removeTags(tags, c.RemoveTags)
+	if strings.Join(tags, ",") != strings.Join(p.Tags, ",") {
+		p.Tags = tags
+		changed = true
+	}
 
-		p, err := ReadProjectFile(baseDir, entry.Name())
+	if c.Fields != nil {
+		fields, err := applyFields(p.Fields, c.Fields, false)
 		if err != nil {
-			log.Printf("⚠️  Skipping %s: %v", entry.Name(), err)
-			continue
+			return p, err
 		}
+		if !maps.Equal(fields, p.Fields) {
+			p.Fields = fields
+			changed = true
+		}
+	}
+
+	if !changed {
+		return p, nil
+	}
 
-		fmt.Printf("%-12s %-25s %-10s %-20s\n", p.ID, p.Name, p.Status, p.CreatedAt)
-		found = true
+	p.UpdatedAt = Timestamp()
+	p.Path = filepath.Join(baseDir, ValidateID(id))
+	if err := WriteProjectFile(p); err != nil {
+		return p, fmt.Errorf("write project file: %w", err)
 	}
 
-	if !found {
-		fmt.Println("📭 No valid projects found.")
+	if changes := diffProjects(before, p); len(changes) > 0 {
+		if err := AppendJournal(p.Path, JournalEntry{Action: ActionUpdate, Changes: changes}); err != nil {
+			return p, err
+		}
+	}
+	if before.Status != p.Status {
+		return p, journalStatus(p.Path, before.Status, p.Status)
 	}
+	return p, nil
 }
 
-func ShowStatus(baseDir, id string) {
-	p, err := ReadProjectFile(baseDir, id)
-	if err != nil {
-		log.Fatalf("Failed to read project: %v", err)
-	}
-
-	fmt.Println("󱖫 Project Status")
-	fmt.Println(strings.Repeat("=", 50))
-	fmt.Printf("ID:          %s\n", p.ID)
-	fmt.Printf("Name:        %s\n", p.Name)
-	fmt.Printf("Description: %s\n", p.Description)
-	fmt.Printf("Status:      %s\n", p.Status)
-	fmt.Printf("Created At:  %s\n", p.CreatedAt)
-	fmt.Printf("Tags:        %s\n", strings.Join(p.Tags, ", "))
-	fmt.Printf("Path:        %s\n", p.Path)
-	fmt.Println(strings.Repeat("=", 50))
+func addTags(tags, add []string) []string {
+	out := append([]string{}, tags...)
+	for _, t := range add {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func removeTags(tags, remove []string) []string {
+	out := []string{}
+	for _, t := range tags {
+		if !slices.Contains(remove, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ListProjects lists the projects in baseDir, with warnings about folders
+// that were skipped.
+func ListProjects(baseDir string) ([]Project, []string, error) {
+	return scanProjects(baseDir, false)
 }
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An ID pattern such as "{customer}-{yy}{seq:03}" or "CP-{seq:04}" builds
+// project IDs from literal text, the date ({yy}, {yyyy}, {mm}), custom
+// field values and exactly one {seq}, the running number. Numbers count up
+// separately for every distinct prefix and suffix, so {yy} starts over at 1
+// each year.
+
+type idToken struct {
+	literal string
+	name    string
+	width   int
+}
+
+var idLiteralRe = regexp.MustCompile(`^[A-Za-z0-9\-]*$`)
+
+func parseIDPattern(pattern string) ([]idToken, error) {
+	var tokens []idToken
+	seqs := 0
+	rest := pattern
+	for rest != "" {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			open = len(rest)
+		}
+		if lit := rest[:open]; lit != "" {
+			if !idLiteralRe.MatchString(lit) {
+				return nil, fmt.Errorf("ID pattern %q: %q may only use letters, digits and -", pattern, lit)
+			}
+			tokens = append(tokens, idToken{literal: lit})
+		}
+		if open == len(rest) {
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("ID pattern %q has an unmatched }", pattern)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return nil, fmt.Errorf("ID pattern %q has an unclosed {", pattern)
+		}
+
+		t := idToken{name: rest[open+1 : open+1+end]}
+		if name, width, ok := strings.Cut(t.name, ":"); ok {
+			n, err := strconv.Atoi(width)
+			if name != "seq" || err != nil || n < 1 || n > 12 {
+				return nil, fmt.Errorf("ID pattern %q: bad token {%s} (only {seq} takes a width, e.g. {seq:04})", pattern, t.name)
+			}
+			t.name, t.width = name, n
+		}
+		switch {
+		case t.name == "seq":
+			seqs++
+		case t.name == "yy", t.name == "yyyy", t.name == "mm":
+		case !fieldNameRe.MatchString(t.name):
+			return nil, fmt.Errorf("ID pattern %q uses unknown token {%s}", pattern, t.name)
+		}
+		tokens = append(tokens, t)
+		rest = rest[open+end+2:]
+	}
+	if seqs != 1 {
+		return nil, fmt.Errorf("ID pattern %q must include {seq} exactly once", pattern)
+	}
+	return tokens, nil
+}
+
+// ValidateIDPattern checks an ID pattern; field tokens must name one of
+// fields. An empty pattern is valid and turns automatic IDs off.
+func ValidateIDPattern(pattern string, fields []FieldDef) error {
+	if pattern == "" {
+		return nil
+	}
+	tokens, err := parseIDPattern(pattern)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		switch t.name {
+		case "", "seq", "yy", "yyyy", "mm":
+			continue
+		}
+		if !slices.ContainsFunc(fields, func(d FieldDef) bool { return d.Name == t.name }) {
+			return fmt.Errorf("ID pattern %q uses {%s}, which is not a custom field", pattern, t.name)
+		}
+	}
+	return nil
+}
+
+// NextProjectID works out the next free ID from PROJMAN_PROJECT_ID_PATTERN,
+// looking at the project folders and archives in baseDir. Field tokens
+// are filled from fields, falling back to the field's default. The ID is
+// only a suggestion until a project is created with it; CreateProject with
+// AutoID set allocates under a lock instead.
+func NextProjectID(baseDir string, fields map[string]string) (string, error) {
+	pattern := config.ProjectIDPattern
+	if pattern == "" {
+		return "", fmt.Errorf("%w: no project ID pattern set (PROJMAN_PROJECT_ID_PATTERN)", ErrInvalidID)
+	}
+	tokens, err := parseIDPattern(pattern)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	now := time.Now()
+	var prefix, suffix strings.Builder
+	width, out := 0, &prefix
+	for _, t := range tokens {
+		switch t.name {
+		case "":
+			out.WriteString(t.literal)
+		case "seq":
+			width, out = t.width, &suffix
+		case "yy":
+			out.WriteString(now.Format("06"))
+		case "yyyy":
+			out.WriteString(now.Format("2006"))
+		case "mm":
+			out.WriteString(now.Format("01"))
+		default:
+			value := fields[t.name]
+			if value == "" {
+				if i := slices.IndexFunc(config.Fields, func(d FieldDef) bool { return d.Name == t.name }); i >= 0 {
+					value = config.Fields[i].Default
+				}
+			}
+			if value = ValidateID(value); value == "" {
+				return "", fmt.Errorf("%w: ID pattern %q needs a value for %s", ErrInvalidID, pattern, t.name)
+			}
+			out.WriteString(value)
+		}
+	}
+
+	before, after := ValidateID(prefix.String()), ValidateID(suffix.String())
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(before) + `(\d+)` + regexp.QuoteMeta(after) + "$")
+	taken, err := takenIDs(baseDir)
+	if err != nil {
+		return "", err
+	}
+	next := 1
+	for _, id := range taken {
+		if m := re.FindStringSubmatch(id); m != nil {
+			if n, err := strconv.Atoi(m[1]); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+	}
+	return fmt.Sprintf("%s%0*d%s", before, width, next, after), nil
+}
+
+// takenIDs lists every name a new project can't use: the folders in
+// baseDir and the archived projects.
+func takenIDs(baseDir string) ([]string, error) {
+	var ids []string
+	entries, err := os.ReadDir(baseDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if isProjectDir(e) {
+			ids = append(ids, e.Name())
+		}
+	}
+	archived, _ := filepath.Glob(filepath.Join(baseDir, ArchiveDir, "*.zip"))
+	for _, path := range archived {
+		ids = append(ids, strings.TrimSuffix(filepath.Base(path), ".zip"))
+	}
+	return ids, nil
+}
+
+// idLockPath is held while an ID is picked and its folder created, so two
+// people creating projects on the same share can't get the same number.
+func idLockPath(baseDir string) string {
+	return filepath.Join(baseDir, indexDirName, "id.lock")
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestParseIDPattern(t *testing.T) {
+	good := []string{"CP-{seq:04}", "{seq}", "{customer}-{yy}{seq:03}", "{yyyy}-{mm}-{seq:2}-X"}
+	for _, p := range good {
+		if _, err := parseIDPattern(p); err != nil {
+			t.Errorf("parseIDPattern(%q): %v", p, err)
+		}
+	}
+	bad := []string{"", "CP-", "{seq}{seq}", "CP_{seq}", "{seq", "seq}", "{yy:2}{seq}", "{seq:0}", "{seq:x}", "{Bad Name}{seq}"}
+	for _, p := range bad {
+		if _, err := parseIDPattern(p); err == nil {
+			t.Errorf("parseIDPattern(%q) accepted", p)
+		}
+	}
+}
+
+func TestNextProjectID(t *testing.T) {
+	saved := config
+	t.Cleanup(func() { config = saved })
+	base := t.TempDir()
+	config = defaultConfig
+	config.BaseDir = base
+
+	for _, dir := range []string{"CP-0003", "CP-0010-OLD", "XCP-0042", "ACME-005-A", "ACME-009-B", "OTHER-012-A"} {
+		if err := os.Mkdir(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.MkdirAll(filepath.Join(base, ArchiveDir), 0755)
+	writeFile(t, filepath.Join(base, ArchiveDir, "CP-0007.zip"), "")
+
+	tests := []struct {
+		pattern string
+		fields  map[string]string
+		want    string
+	}{
+		// The archived CP-0007 counts; CP-0010-OLD and XCP-0042 don't match.
+		{"CP-{seq:04}", nil, "CP-0008"},
+		{"{customer}-{seq:03}-A", map[string]string{"customer": "acme"}, "ACME-006-A"},
+		{"{customer}-{seq:03}-A", map[string]string{"customer": "new"}, "NEW-001-A"},
+	}
+	for _, tt := range tests {
+		config.ProjectIDPattern = tt.pattern
+		got, err := NextProjectID(base, tt.fields)
+		if err != nil || got != tt.want {
+			t.Errorf("NextProjectID(%q, %v) = %q, %v; want %q", tt.pattern, tt.fields, got, err, tt.want)
+		}
+	}
+
+	config.ProjectIDPattern = "{customer}-{seq}"
+	if _, err := NextProjectID(base, nil); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("missing field value: err = %v, want ErrInvalidID", err)
+	}
+}
+
+func TestCreateProjectIDsDontCollide(t *testing.T) {
+	saved := config
+	t.Cleanup(func() { config = saved })
+	base := t.TempDir()
+	config = defaultConfig
+	config.BaseDir = base
+	config.ProjectIDPattern = "CP-{seq:04}"
+
+	if _, err := CreateProject(base, Params{ID: "CP-0001", Name: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateProject(base, Params{ID: "cp-0001", Name: "again"}); !errors.Is(err, ErrProjectExists) {
+		t.Errorf("second CP-0001: err = %v, want ErrProjectExists", err)
+	}
+
+	var wg sync.WaitGroup
+	ids := make([]string, 6)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p, err := CreateProject(base, Params{AutoID: true, Name: "auto"})
+			if err != nil {
+				t.Error(err)
+			}
+			ids[i] = p.ID
+		}(i)
+	}
+	wg.Wait()
+	seen := map[string]bool{"CP-0001": true}
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("%s handed out twice", id)
+		}
+		seen[id] = true
+	}
+}
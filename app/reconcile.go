@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PresetReport compares a project folder with a preset. Missing entries are
+// what the preset expects but the project lacks; extra folders are top-level
+// folders the preset does not know about, which are never touched.
+type PresetReport struct {
+	ID             string
+	Preset         string
+	MissingFolders []string
+	MissingFiles   []string
+	ExtraFolders   []string
+	// Applied is set once the missing folders and files have been created.
+	Applied bool
+}
+
+// InSync reports whether the project has everything the preset asks for.
+func (r PresetReport) InSync() bool {
+	return len(r.MissingFolders) == 0 && len(r.MissingFiles) == 0
+}
+
+// ApplyPreset reconciles an existing project with a preset. An empty name
+// means the preset the project was created from. With check set the project
+// is only inspected; otherwise missing folders and files are created, and
+// nothing that already exists is changed or removed.
+func ApplyPreset(baseDir, id, name string, vars map[string]string, check bool) (PresetReport, error) {
+	p, err := ReadProjectFile(baseDir, id)
+	if err != nil {
+		return PresetReport{}, err
+	}
+	p.Path = filepath.Join(baseDir, ValidateID(id))
+	if name == "" {
+		name = p.Preset
+	}
+	preset, err := LoadPreset(baseDir, name)
+	if err != nil {
+		return PresetReport{}, err
+	}
+
+	r := PresetReport{ID: p.ID, Preset: preset.Name}
+	before := p
+	p.Preset = preset.Name
+	data := newTemplateData(p, preset, vars)
+
+	folders, err := folderPaths(preset.Folders, data, "")
+	if err != nil {
+		return r, err
+	}
+	top := []string{journalDir}
+	for _, f := range folders {
+		if !exists(filepath.Join(p.Path, filepath.FromSlash(f))) {
+			r.MissingFolders = append(r.MissingFolders, f)
+		}
+		first, _, _ := strings.Cut(f, "/")
+		top = append(top, first)
+	}
+	for _, f := range preset.Files {
+		rel, err := renderPath(f, data)
+		if err != nil {
+			return r, err
+		}
+		if !exists(filepath.Join(p.Path, filepath.FromSlash(rel))) {
+			r.MissingFiles = append(r.MissingFiles, rel)
+		}
+	}
+
+	entries, err := os.ReadDir(p.Path)
+	if err != nil {
+		return r, err
+	}
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && !slices.Contains(top, e.Name()) {
+			r.ExtraFolders = append(r.ExtraFolders, e.Name())
+		}
+	}
+
+	if check || (r.InSync() && before.Preset == p.Preset) {
+		return r, nil
+	}
+
+	if err := buildFolders(p.Path, preset.Folders, data); err != nil {
+		return r, err
+	}
+	if _, err := renderFiles(p.Path, preset.Files, data); err != nil {
+		return r, err
+	}
+	r.Applied = true
+
+	var changes []FieldChange
+	if before.Preset != p.Preset {
+		p.UpdatedAt = Timestamp()
+		if err := WriteProjectFile(p); err != nil {
+			return r, fmt.Errorf("write project file: %w", err)
+		}
+		changes = append(changes, FieldChange{Field: "preset", Old: before.Preset, New: p.Preset})
+	}
+	created := append(append([]string{}, r.MissingFolders...), r.MissingFiles...)
+	note := "nothing missing"
+	if len(created) > 0 {
+		note = "created " + strings.Join(created, ", ")
+	}
+	return r, AppendJournal(p.Path, JournalEntry{Action: ActionPreset, Changes: changes, Note: note})
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The tag registry pins every generated tag's instance number to its name
+// so regenerating from an edited CSV never renumbers tags that are already
+// printed on nameplates. It lives in the project root as tag-registry.yaml.
+// Numbers are never handed out twice: tags that drop out of the CSV they
+// were generated from are retired, keep their number, and get it back if
+// they return. Tags from the project's other tag lists are left alone.
+const TagRegistryFile = "tag-registry.yaml"
+
+type TagRegistry struct {
+	Tags []RegisteredTag `yaml:"tags"`
+}
+
+type RegisteredTag struct {
+	Category string `yaml:"category"`
+	Subcat   string `yaml:"subcat"`
+	Name     string `yaml:"name"`
+	Parent   string `yaml:"parent,omitempty"`
+	// Source is the input file the tag was last generated from, relative
+	// to the registry.
+	Source string `yaml:"source,omitempty"`
+	Number int    `yaml:"number"`
+	// Tag is the tag as last generated from Number.
+	Tag      string `yaml:"tag"`
+	Assigned string `yaml:"assigned"`
+	Retired  string `yaml:"retired,omitempty"`
+}
+
+// TagRegistryPath is where the registry for tags written to outputPath
+// lives: the root of the project holding outputPath, or the output folder
+// when it isn't inside a project.
+func TagRegistryPath(outputPath string) string {
+	if root, ok := FindProjectRoot(outputPath); ok {
+		return filepath.Join(root, TagRegistryFile)
+	}
+	return filepath.Join(filepath.Dir(outputPath), TagRegistryFile)
+}
+
+// registrySource is how inputPath is recorded as a tag's source in the
+// registry at registryPath.
+func registrySource(registryPath, inputPath string) string {
+	abs, err := filepath.Abs(inputPath)
+	if err != nil {
+		return filepath.ToSlash(inputPath)
+	}
+	if dir, err := filepath.Abs(filepath.Dir(registryPath)); err == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(abs)
+}
+
+// LoadTagRegistry reads the registry at path; a missing file is empty.
+func LoadTagRegistry(path string) (TagRegistry, error) {
+	var r TagRegistry
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("read tag registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+func (r TagRegistry) save(path string) error {
+	sort.SliceStable(r.Tags, func(i, j int) bool {
+		a, b := r.Tags[i], r.Tags[j]
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Subcat != b.Subcat {
+			return a.Subcat < b.Subcat
+		}
+		return a.Number < b.Number
+	})
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// noBlock is the block of a row that doesn't share its equipment's number.
+const noBlock = -1
+
+// assign returns the index of the registry entry for row and whether it
+// was just added, recording source as where it came from. A new entry gets
+// block unless that is noBlock, which is how instruments share their
+// equipment's number, and otherwise the number after every one ever used
+// by the entries group matches (start for the first). An existing entry
+// keeps its number, or moves to block, and is brought back if it was
+// retired.
+func (r *TagRegistry) assign(row TagRow, source string, start, block int, group func(RegisteredTag) bool) (int, bool) {
+	for i := range r.Tags {
+		t := &r.Tags[i]
+		if t.Category == row.Category && t.Subcat == row.Subcat && t.Name == row.Name && t.Parent == row.Parent {
+			t.Retired, t.Source = "", source
+			if block != noBlock {
+				t.Number = block
+			}
+			return i, false
+		}
+	}
+
+	next := block
+	if next == noBlock {
+		next = start
+		for _, t := range r.Tags {
+			if group(t) {
+				next = max(next, t.Number+1)
+			}
+		}
+	}
+	r.Tags = append(r.Tags, RegisteredTag{
+		Category: row.Category,
+		Subcat:   row.Subcat,
+		Name:     row.Name,
+		Parent:   row.Parent,
+		Source:   source,
+		Number:   next,
+		Assigned: Timestamp(),
+	})
+	return len(r.Tags) - 1, true
+}
+
+// retire marks the active tags from source whose index is not in seen as
+// retired and returns how many it marked. Tags recorded before sources
+// were kept have none and are left alone until they are generated again.
+func (r *TagRegistry) retire(source string, seen map[int]bool) int {
+	retired := 0
+	for i := range r.Tags {
+		t := &r.Tags[i]
+		if t.Retired == "" && t.Source == source && !seen[i] {
+			t.Retired = Timestamp()
+			retired++
+		}
+	}
+	return retired
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion is the project.yaml layout this binary writes.
+// Files without a schema_version are treated as version 0.
+const CurrentSchemaVersion = 3
+
+// A migration upgrades a decoded project.yaml from one version to the next
+// and describes what it changed.
+type migration struct {
+	from  int
+	apply func(doc map[string]any) []string
+}
+
+// migrations must stay ordered by from, one per version, with no gaps.
+var migrations = []migration{
+	{from: 0, apply: migrateV0},
+	{from: 1, apply: migrateV1},
+	{from: 2, apply: migrateV2},
+}
+
+// migrateV0 covers files written before versioning: hand-edited tags given
+// as a comma-separated string, or none at all. A missing updated_at stays
+// missing, since the project has not been updated.
+func migrateV0(doc map[string]any) []string {
+	var notes []string
+	if tags, ok := doc["tags"].(string); ok {
+		doc["tags"] = CleanTags(tags)
+		notes = append(notes, "converted tags from a string to a list")
+	}
+	if doc["tags"] == nil {
+		doc["tags"] = []string{}
+	}
+	return notes
+}
+
+// migrateV1 has nothing to convert: version 2 adds the optional preset
+// field, and the bump keeps older binaries from dropping it.
+func migrateV1(doc map[string]any) []string {
+	return nil
+}
+
+// migrateV2 has nothing to convert either: version 3 adds the optional
+// fields map for custom fields.
+func migrateV2(doc map[string]any) []string {
+	return nil
+}
+
+// decodeProject upgrades raw project.yaml data to CurrentSchemaVersion and
+// decodes it. It refuses files written by a newer projman, since this
+// binary would drop the fields it does not know about on the next write.
+func decodeProject(data []byte) (Project, int, []string, error) {
+	var p Project
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return p, 0, nil, err
+	}
+
+	version := 0
+	if v, ok := doc["schema_version"]; ok {
+		n, ok := v.(int)
+		if !ok {
+			return p, 0, nil, fmt.Errorf("schema_version %v is not a number", v)
+		}
+		version = n
+	}
+	if version > CurrentSchemaVersion {
+		return p, version, nil, fmt.Errorf("%w: schema_version %d, this projman understands up to %d",
+			ErrSchemaTooNew, version, CurrentSchemaVersion)
+	}
+
+	var notes []string
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		for _, note := range m.apply(doc) {
+			notes = append(notes, fmt.Sprintf("v%d→v%d: %s", m.from, m.from+1, note))
+		}
+	}
+	if version < CurrentSchemaVersion {
+		doc["schema_version"] = CurrentSchemaVersion
+		notes = append(notes, fmt.Sprintf("set schema_version %d", CurrentSchemaVersion))
+	}
+
+	upgraded, err := yaml.Marshal(doc)
+	if err != nil {
+		return p, version, nil, err
+	}
+	if err := yaml.Unmarshal(upgraded, &p); err != nil {
+		return p, version, nil, err
+	}
+	return p, version, notes, nil
+}
+
+type MigrationResult struct {
+	ID      string
+	From    int
+	Changes []string
+	Err     error
+}
+
+// MigrateProjects upgrades every project.yaml in baseDir to the current
+// schema. With dryRun set nothing is written; the results still say what
+// would change.
+func MigrateProjects(baseDir string, dryRun bool) ([]MigrationResult, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("read base directory: %w", err)
+	}
+
+	var results []MigrationResult
+	for _, entry := range entries {
+		if !isProjectDir(entry) {
+			continue
+		}
+		path := filepath.Join(baseDir, entry.Name(), "project.yaml")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		r := MigrationResult{ID: entry.Name()}
+		if err != nil {
+			r.Err = err
+			results = append(results, r)
+			continue
+		}
+
+		p, from, notes, err := decodeProject(data)
+		r.From, r.Changes, r.Err = from, notes, err
+		if err == nil && len(notes) > 0 && !dryRun {
+			p.Path = filepath.Join(baseDir, entry.Name())
+			if err := WriteProjectFile(p); err != nil {
+				r.Err = fmt.Errorf("write project file: %w", err)
+			} else {
+				r.Err = AppendJournal(p.Path, JournalEntry{
+					Action:  ActionMigrate,
+					Changes: []FieldChange{{Field: "schema_version", Old: fmt.Sprint(from), New: fmt.Sprint(CurrentSchemaVersion)}},
+					Note:    strings.Join(notes, "; "),
+				})
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
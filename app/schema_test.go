@@ -0,0 +1,46 @@
+package app
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeProjectV0(t *testing.T) {
+	data := []byte("id: CP-1\nname: Old\nstatus: active\ntags: \"plc, hmi ,plc\"\ncreated_at: 2020-01-02T03:04:05Z\n")
+	p, from, notes, err := decodeProject(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if from != 0 {
+		t.Errorf("from = %d, want 0", from)
+	}
+	if want := CleanTags("plc, hmi ,plc"); !reflect.DeepEqual(p.Tags, want) {
+		t.Errorf("tags = %q, want %q", p.Tags, want)
+	}
+	if p.UpdatedAt != "" {
+		t.Errorf("updated_at = %q, want it left empty", p.UpdatedAt)
+	}
+	if p.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", p.SchemaVersion, CurrentSchemaVersion)
+	}
+	want := []string{"v0→v1: converted tags from a string to a list", "set schema_version 3"}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("notes = %q, want %q", notes, want)
+	}
+
+	current := []byte("schema_version: 3\nid: CP-2\ntags: []\n")
+	if _, _, notes, err := decodeProject(current); err != nil || len(notes) != 0 {
+		t.Errorf("current file: notes %q, err %v; want neither", notes, err)
+	}
+}
+
+func TestDecodeProjectTooNew(t *testing.T) {
+	_, from, _, err := decodeProject([]byte("schema_version: 99\nid: CP-3\n"))
+	if !errors.Is(err, ErrSchemaTooNew) || from != 99 {
+		t.Errorf("from %d, err %v; want 99 and ErrSchemaTooNew", from, err)
+	}
+	if _, _, _, err := decodeProject([]byte("schema_version: soon\n")); err == nil {
+		t.Error("non-numeric schema_version accepted")
+	}
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// StarterPresets can be written into a new base directory by the setup
+// wizard so there is something to choose from besides the default.
+var StarterPresets = map[string]Preset{
+	"controls": {
+		Name: "controls",
+		Folders: []Folder{
+			{Name: "Docs"},
+			{Name: "Electrical", Folders: []Folder{
+				{Name: "Drawings"},
+				{Name: "PLC", Folders: []Folder{{Name: "Backups"}, {Name: "Exports"}}},
+				{Name: "HMI"},
+			}},
+			{Name: "Planning"}, {Name: "Logs"}, {Name: "Exports"},
+		},
+	},
+	"software": {
+		Name: "software",
+		Folders: []Folder{
+			{Name: "Docs"}, {Name: "Source"}, {Name: "Tests"}, {Name: "Releases"}, {Name: "Logs"},
+		},
+	},
+	"minimal": {
+		Name:    "minimal",
+		Folders: []Folder{{Name: "Docs"}, {Name: "Logs"}},
+	},
+}
+
+// FirstRun reports whether projman has not been set up yet: none of the
+// config files LoadConfig reads exist.
+func FirstRun() bool {
+	paths := []string{SystemConfigPath, LocalConfigPath(config.BaseDir)}
+	if user, err := UserConfigPath(); err == nil {
+		paths = append(paths, user)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			return false
+		}
+	}
+	return true
+}
+
+// InitConfig writes the settings the wizard changed in c to the user config
+// file, creating the base directory if needed, and reloads the
+// configuration. Values left as they were keep coming from the defaults or
+// the system file, so later changes there still apply. The file is written
+// even when nothing changed so the wizard doesn't run again.
+func InitConfig(c Config) (Config, error) {
+	c.BaseDir = ExpandHome(c.BaseDir)
+	if err := os.MkdirAll(c.BaseDir, 0755); err != nil {
+		return config, fmt.Errorf("create base directory: %w", err)
+	}
+	if err := ValidateConfig(c); err != nil {
+		return config, err
+	}
+
+	path, err := UserConfigPath()
+	if err != nil {
+		return config, fmt.Errorf("save config: %w", err)
+	}
+	values, err := readConfigFile(path)
+	if err != nil {
+		return config, err
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	for _, s := range settings {
+		if value := s.get(c); value != s.get(config) {
+			values[s.key] = value
+		}
+	}
+	data, err := godotenv.Marshal(values)
+	if err != nil {
+		return config, fmt.Errorf("save config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return config, fmt.Errorf("save config: %w", err)
+	}
+	if err := writeFileAtomic(path, []byte(data+"\n"), 0644); err != nil {
+		return config, fmt.Errorf("save config: %w", err)
+	}
+	return LoadConfig(loadedFlags)
+}
+
+// WriteStarterPreset saves one of StarterPresets into baseDir. An existing
+// preset with the same name is left alone.
+func WriteStarterPreset(baseDir, name string) error {
+	p, ok := StarterPresets[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPresetNotFound, name)
+	}
+	if _, _, err := readPresetFile(baseDir, name); err == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(PresetsDir(baseDir), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(PresetsDir(baseDir), name+".yaml"), data, 0644)
+}
+
+// StarterPresetNames lists StarterPresets in a stable order.
+func StarterPresetNames() []string {
+	names := make([]string, 0, len(StarterPresets))
+	for name := range StarterPresets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ImportCandidates lists the folders in baseDir that have no project.yaml
+// and so could be adopted with ImportProject.
+func ImportCandidates(baseDir string) ([]string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read base directory: %w", err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		if !isProjectDir(e) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(baseDir, e.Name(), "project.yaml")); errors.Is(err, fs.ErrNotExist) {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	return dirs, nil
+}
+
+// ImportProject adopts an existing folder in baseDir as a project by
+// writing its project.yaml. The folder name must already be a valid ID,
+// since projects are looked up by folder name.
+func ImportProject(baseDir, dir string) (Project, error) {
+	id := ValidateID(dir)
+	if id == "" || id != dir {
+		return Project{}, fmt.Errorf("%w: folder %q is not a valid project ID; rename it first", ErrInvalidID, dir)
+	}
+	path := filepath.Join(baseDir, dir)
+	if _, err := os.Stat(filepath.Join(path, "project.yaml")); err == nil {
+		return Project{}, fmt.Errorf("%w: %s", ErrProjectExists, id)
+	}
+
+	p := Project{
+		ID:        id,
+		Name:      dir,
+		Tags:      []string{},
+		CreatedAt: Timestamp(),
+		Path:      path,
+	}
+	if _, err := changeStatus(&p, config.DefaultStatus); err != nil {
+		return p, err
+	}
+	if err := WriteProjectFile(p); err != nil {
+		return p, fmt.Errorf("write project file: %w", err)
+	}
+	changes := append([]FieldChange{{Field: "id", New: id}}, diffProjects(Project{}, p)...)
+	changes = append(changes, FieldChange{Field: "status", New: p.Status})
+	return p, AppendJournal(path, JournalEntry{Action: ActionImport, Changes: changes, Note: "imported existing folder"})
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+)
+
+// StatusChange records one move through the project lifecycle.
+type StatusChange struct {
+	From string `yaml:"from,omitempty"`
+	To   string `yaml:"to"`
+	At   string `yaml:"at"`
+	By   string `yaml:"by"`
+}
+
+var defaultStatuses = []string{
+	"quote", "awarded", "design", "build", "FAT", "SAT",
+	"commissioning", "warranty", "closed", StatusArchived,
+}
+
+var defaultTransitions = map[string][]string{
+	"quote":         {"awarded"},
+	"awarded":       {"design"},
+	"design":        {"build"},
+	"build":         {"FAT", "design"},
+	"FAT":           {"SAT", "build"},
+	"SAT":           {"commissioning", "build"},
+	"commissioning": {"warranty", "SAT"},
+	"warranty":      {"closed"},
+	"closed":        {StatusArchived, "warranty"},
+	StatusArchived:  {"closed"},
+}
+
+// CanonicalStatus matches status against the configured list ignoring case,
+// so "fat" becomes "FAT". An empty list accepts any non-empty status.
+func CanonicalStatus(status string) (string, error) {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return "", fmt.Errorf("%w: status is empty", ErrInvalidStatus)
+	}
+	if len(config.Statuses) == 0 {
+		return status, nil
+	}
+	for _, s := range config.Statuses {
+		if strings.EqualFold(s, status) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q (want one of %s)", ErrInvalidStatus, status, strings.Join(config.Statuses, ", "))
+}
+
+// AllowedTransitions lists the statuses a project may move to from "from".
+// Projects whose status predates the configured lifecycle may move to any
+// configured status so they can be brought back in line.
+func AllowedTransitions(from string) []string {
+	if next, ok := config.StatusTransitions[from]; ok {
+		return next
+	}
+	if !slices.Contains(config.Statuses, from) {
+		return config.Statuses
+	}
+	return nil
+}
+
+// CheckTransition returns ErrInvalidTransition unless a project may move
+// from one status to the other. Without configured transitions every move
+// between valid statuses is allowed.
+func CheckTransition(from, to string) error {
+	if from == to || len(config.StatusTransitions) == 0 {
+		return nil
+	}
+	if !slices.Contains(AllowedTransitions(from), to) {
+		return fmt.Errorf("%w: %s → %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
+
+// changeStatus validates and applies a status change, recording it in the
+// project's history. It reports whether the status actually changed.
+// Keeping a status that predates the configured list is not a change.
+func changeStatus(p *Project, status string) (bool, error) {
+	if p.Status != "" && strings.TrimSpace(status) == p.Status {
+		return false, nil
+	}
+	to, err := CanonicalStatus(status)
+	if err != nil {
+		return false, err
+	}
+	if to == p.Status {
+		return false, nil
+	}
+	if err := CheckTransition(p.Status, to); err != nil {
+		return false, err
+	}
+
+	p.StatusHistory = append(p.StatusHistory, StatusChange{
+		From: p.Status,
+		To:   to,
+		At:   Timestamp(),
+		By:   CurrentUser(),
+	})
+	p.Status = to
+	return true, nil
+}
+
+// CurrentUser names whoever is running projman for history and journal entries.
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, key := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(key); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// parseTransitions reads "quote:awarded|closed;awarded:design" into a map.
+func parseTransitions(s string) (map[string][]string, error) {
+	transitions := map[string][]string{}
+	for _, rule := range strings.Split(s, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(rule, ":")
+		from = strings.TrimSpace(from)
+		if !ok || from == "" {
+			return nil, fmt.Errorf("transition %q must look like from:to|to", rule)
+		}
+		var next []string
+		for _, t := range strings.Split(to, "|") {
+			if t = strings.TrimSpace(t); t != "" {
+				next = append(next, t)
+			}
+		}
+		transitions[from] = next
+	}
+	return transitions, nil
+}
+
+func formatTransitions(transitions map[string][]string) string {
+	froms := make([]string, 0, len(transitions))
+	for from := range transitions {
+		froms = append(froms, from)
+	}
+	slices.Sort(froms)
+
+	rules := make([]string, len(froms))
+	for i, from := range froms {
+		rules[i] = from + ":" + strings.Join(transitions[from], "|")
+	}
+	return strings.Join(rules, ";")
+}
+
+// validateLifecycle checks that the default status and every transition
+// only refer to configured statuses.
+func validateLifecycle(c Config) error {
+	if len(c.Statuses) == 0 {
+		return nil
+	}
+	if c.DefaultStatus != "" && !slices.Contains(c.Statuses, c.DefaultStatus) {
+		return fmt.Errorf("%w: default status %q is not in PROJMAN_STATUSES", ErrInvalidConfig, c.DefaultStatus)
+	}
+	for from, next := range c.StatusTransitions {
+		for _, s := range append([]string{from}, next...) {
+			if !slices.Contains(c.Statuses, s) {
+				return fmt.Errorf("%w: transition status %q is not in PROJMAN_STATUSES", ErrInvalidConfig, s)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckTransitionDefaults(t *testing.T) {
+	saved := config
+	t.Cleanup(func() { config = saved })
+	config = defaultConfig
+
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"quote", "awarded", true},
+		{"quote", "closed", false},
+		{"awarded", "closed", false},
+		{"design", "closed", false},
+		{"warranty", "closed", true},
+		{"closed", StatusArchived, true},
+		{"quote", "quote", true},
+		{"active", "design", true},
+	}
+	for _, tt := range tests {
+		err := CheckTransition(tt.from, tt.to)
+		if tt.ok && err != nil {
+			t.Errorf("CheckTransition(%q, %q) = %v", tt.from, tt.to, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("CheckTransition(%q, %q) = %v, want ErrInvalidTransition", tt.from, tt.to, err)
+		}
+	}
+}
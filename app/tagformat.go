@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A tag format is literal text with {token} placeholders, for example
+//
+//	{system:04}-{category}-{subcat}-{id:04}[-{redundancy|upper}]
+//
+// Tokens are category, subcat, name, id and any CSV column, named by its
+// header in lower case with spaces as underscores. ":04" pads the value
+// with zeros to four characters (":4" pads with spaces), and "|upper" or
+// "|lower" change its case. Text in [brackets] is dropped when any token
+// inside it is empty; outside brackets an empty token is an error.
+// A backslash escapes the next character.
+
+// DefaultIDWidth keeps a bare {id} as wide as it always was.
+const DefaultIDWidth = 2
+
+var (
+	tokenNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	tagFilters  = map[string]func(string) string{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+)
+
+type TagFormat struct {
+	format string
+	parts  []tagPart
+}
+
+// tagPart is literal text, a token, or an optional group of parts.
+type tagPart struct {
+	literal  string
+	token    string
+	width    int
+	zero     bool
+	filters  []string
+	optional []tagPart
+}
+
+// ParseTagFormat parses format, which must include {id}.
+func ParseTagFormat(format string) (TagFormat, error) {
+	f := TagFormat{format: format}
+	fail := func(pos int, msg string, args ...any) (TagFormat, error) {
+		return TagFormat{}, fmt.Errorf("tag format %q, column %d: %s", format, pos+1, fmt.Sprintf(msg, args...))
+	}
+
+	var group []tagPart
+	inGroup, groupStart := false, 0
+	add := func(p tagPart) {
+		if inGroup {
+			group = append(group, p)
+		} else {
+			f.parts = append(f.parts, p)
+		}
+	}
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			add(tagPart{literal: lit.String()})
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		switch c := format[i]; c {
+		case '\\':
+			if i+1 == len(format) {
+				return fail(i, "nothing to escape after \\")
+			}
+			i++
+			lit.WriteByte(format[i])
+		case '[':
+			if inGroup {
+				return fail(i, "optional segments can't be nested")
+			}
+			flush()
+			inGroup, groupStart = true, i
+		case ']':
+			if !inGroup {
+				return fail(i, "] without a matching [")
+			}
+			flush()
+			if !hasToken(group) {
+				return fail(groupStart, "optional segment has no token to depend on")
+			}
+			f.parts = append(f.parts, tagPart{optional: group})
+			group, inGroup = nil, false
+		case '{':
+			end := strings.IndexAny(format[i+1:], "{}")
+			if end < 0 || format[i+1+end] != '}' {
+				return fail(i, "unclosed {")
+			}
+			p, err := parseTagToken(format[i+1 : i+1+end])
+			if err != nil {
+				return fail(i, "%v", err)
+			}
+			flush()
+			add(p)
+			i += end + 1
+		case '}':
+			return fail(i, "} without a matching {")
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	if inGroup {
+		return fail(groupStart, "unclosed [")
+	}
+	flush()
+
+	if !f.uses("id") {
+		return TagFormat{}, fmt.Errorf("tag format %q must include {id}", format)
+	}
+	return f, nil
+}
+
+// parseTagToken reads the inside of {name:width|filter|filter}.
+func parseTagToken(s string) (tagPart, error) {
+	name, filters, _ := strings.Cut(s, "|")
+	name, spec, hasSpec := strings.Cut(name, ":")
+	p := tagPart{token: strings.TrimSpace(name)}
+	if !tokenNameRe.MatchString(p.token) {
+		return p, fmt.Errorf("{%s} is not a valid token name", s)
+	}
+	if hasSpec {
+		n, err := strconv.Atoi(spec)
+		if err != nil || n < 1 || n > 20 || strings.HasPrefix(spec, "+") {
+			return p, fmt.Errorf("{%s}: width %q must be a number like 4 or 04", s, spec)
+		}
+		p.width, p.zero = n, strings.HasPrefix(spec, "0")
+	} else if p.token == "id" {
+		p.width, p.zero = DefaultIDWidth, true
+	}
+	if filters != "" {
+		for _, name := range strings.Split(filters, "|") {
+			if _, ok := tagFilters[name]; !ok {
+				return p, fmt.Errorf("{%s}: unknown filter %q (want upper or lower)", s, name)
+			}
+			p.filters = append(p.filters, name)
+		}
+	}
+	return p, nil
+}
+
+func hasToken(parts []tagPart) bool {
+	for _, p := range parts {
+		if p.token != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f TagFormat) uses(token string) bool {
+	for _, t := range f.Tokens() {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+// Tokens lists the tokens the format uses, in order of appearance.
+func (f TagFormat) Tokens() []string {
+	var tokens []string
+	var walk func([]tagPart)
+	walk = func(parts []tagPart) {
+		for _, p := range parts {
+			if p.token != "" {
+				tokens = append(tokens, p.token)
+			}
+			walk(p.optional)
+		}
+	}
+	walk(f.parts)
+	return tokens
+}
+
+// requiredTokens lists the tokens outside optional segments, which must
+// always have a value.
+func (f TagFormat) requiredTokens() []string {
+	var tokens []string
+	for _, p := range f.parts {
+		if p.token != "" {
+			tokens = append(tokens, p.token)
+		}
+	}
+	return tokens
+}
+
+// renderToken renders value the way the first {token} in the format
+// would, with its padding and filters.
+func (f TagFormat) renderToken(token, value string) string {
+	var found *tagPart
+	var walk func([]tagPart)
+	walk = func(parts []tagPart) {
+		for i := range parts {
+			if found == nil && parts[i].token == token {
+				found = &parts[i]
+			}
+			walk(parts[i].optional)
+		}
+	}
+	walk(f.parts)
+	if found == nil {
+		return value
+	}
+	return found.render(map[string]string{token: value})
+}
+
+func (f TagFormat) String() string {
+	return f.format
+}
+
+// Render fills in the format from values. Values for tokens outside an
+// optional segment must not be empty.
+func (f TagFormat) Render(values map[string]string) (string, error) {
+	var b strings.Builder
+	for _, p := range f.parts {
+		if p.optional != nil {
+			var seg strings.Builder
+			empty := false
+			for _, q := range p.optional {
+				if q.token != "" && values[q.token] == "" {
+					empty = true
+					break
+				}
+				seg.WriteString(q.render(values))
+			}
+			if !empty {
+				b.WriteString(seg.String())
+			}
+			continue
+		}
+		if p.token != "" && values[p.token] == "" {
+			return "", fmt.Errorf("{%s} is empty; wrap it in [ ] in the tag format to make it optional", p.token)
+		}
+		b.WriteString(p.render(values))
+	}
+	return b.String(), nil
+}
+
+func (p tagPart) render(values map[string]string) string {
+	if p.token == "" {
+		return p.literal
+	}
+	v := values[p.token]
+	for _, name := range p.filters {
+		v = tagFilters[name](v)
+	}
+	if pad := p.width - len(v); pad > 0 {
+		fill := " "
+		if p.zero {
+			fill = "0"
+		}
+		v = strings.Repeat(fill, pad) + v
+	}
+	return v
+}
+
+// TagColumn turns a CSV header into the token that refers to it.
+func TagColumn(header string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(header)), " ", "_")
+}
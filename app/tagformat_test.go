@@ -0,0 +1,60 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagFormatRender(t *testing.T) {
+	values := map[string]string{"category": "cat", "subcat": "PMP", "id": "7", "system": "12", "redundancy": "a"}
+	tests := []struct {
+		format, want string
+	}{
+		{"{category}-{subcat}-{id}", "cat-PMP-07"},
+		{"{category|upper}-{subcat|lower}-{id:04}", "CAT-pmp-0007"},
+		{"{system:04}-{id:3}", "0012-  7"},
+		{"{id}[-{redundancy|upper}]", "07-A"},
+		{"{id}[-{missing}]", "07"},
+		{"{id}[-{redundancy}+{missing}]", "07"},
+		{`\{{id}\}\[x\]\\`, `{07}[x]\`},
+		{"{category|upper|lower}{id}", "cat07"},
+	}
+	for _, tt := range tests {
+		f, err := ParseTagFormat(tt.format)
+		if err != nil {
+			t.Errorf("ParseTagFormat(%q): %v", tt.format, err)
+			continue
+		}
+		if got, err := f.Render(values); err != nil || got != tt.want {
+			t.Errorf("%q renders %q, %v; want %q", tt.format, got, err, tt.want)
+		}
+	}
+
+	f, _ := ParseTagFormat("{missing}-{id}")
+	if _, err := f.Render(values); err == nil {
+		t.Error("an empty token outside brackets should be an error")
+	}
+}
+
+func TestParseTagFormatErrors(t *testing.T) {
+	tests := map[string]string{
+		"{category}-{subcat}": "must include {id}",
+		"{id}[-[{x}]]":        "column 7: optional segments can't be nested",
+		"{id}[-x]":            "column 5: optional segment has no token",
+		"{id}]":               "column 5: ] without a matching [",
+		"{id}[-{x}":           "column 5: unclosed [",
+		"{id":                 "column 1: unclosed {",
+		"{id}}":               "column 5: } without a matching {",
+		"{id:x}":              `width "x" must be a number`,
+		"{id:0}":              `width "0" must be a number`,
+		"{id|title}":          `unknown filter "title"`,
+		"{Bad-Name}{id}":      "is not a valid token name",
+		`{id}\`:               "nothing to escape",
+	}
+	for format, want := range tests {
+		_, err := ParseTagFormat(format)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseTagFormat(%q) = %v, want an error containing %q", format, err, want)
+		}
+	}
+}
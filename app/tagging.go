@@ -3,7 +3,11 @@ package app
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -13,6 +17,8 @@ type TagRow struct {
 	Category string
 	Subcat   string
 	Name     string
+	// Parent names the equipment an instrument belongs to.
+	Parent string
 }
 
 type TagAssignment struct {
@@ -20,32 +26,177 @@ type TagAssignment struct {
 	Category string `yaml:"category"`
 	Subcat   string `yaml:"subcat"`
 	Name     string `yaml:"name"`
+
+	// The IEC 81346 aspects and the whole designation in
+	// PROJMAN_DESIGNATION_FORM, when the CSV has designation columns.
+	Function    string `yaml:"function,omitempty"`
+	Product     string `yaml:"product,omitempty"`
+	Location    string `yaml:"location,omitempty"`
+	Designation string `yaml:"designation,omitempty"`
 }
 
-func GenerateTags(csvPath, outputPath string) error {
-	if config.TaggingFormat == "" {
-		return fmt.Errorf("missing tag format in env")
+// ValidateTaggingFormat checks that a tag format parses and includes
+// {id}, without which every tag would collide.
+func ValidateTaggingFormat(format string) error {
+	_, err := ParseTagFormat(format)
+	return err
+}
+
+// TagReport sums up a GenerateTags run.
+type TagReport struct {
+	Tags     int
+	New      int
+	Retired  int
+	Registry string
+}
+
+// tagTable is the input to GenerateTags: a header and rows, from a CSV
+// file or read back from the nested YAML. line is where each row came
+// from, for error messages.
+type tagTable struct {
+	headers []string
+	columns []string
+	rows    [][]string
+	lines   []int
+}
+
+func (t tagTable) has(column string) bool {
+	return slices.Contains(t.columns, column)
+}
+
+// cell returns a row's value for column, or "" when there is none.
+func (t tagTable) cell(row []string, column string) string {
+	if i := slices.Index(t.columns, column); i >= 0 && i < len(row) {
+		return strings.TrimSpace(row[i])
 	}
+	return ""
+}
 
-	f, err := os.Open(csvPath)
+func readTagCSV(path string) (tagTable, error) {
+	var t tagTable
+	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("open csv: %w", err)
+		return t, fmt.Errorf("open csv: %w", err)
 	}
 	defer f.Close()
 	reader := csv.NewReader(f)
 
-	records, err := reader.ReadAll()
-	if err != nil {
-		return fmt.Errorf("read csv: %w", err)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return t, fmt.Errorf("read csv: %w", err)
+		}
+		if t.headers == nil {
+			t.headers = row
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		t.rows = append(t.rows, row)
+		t.lines = append(t.lines, line)
+	}
+	if t.headers == nil {
+		return t, fmt.Errorf("%s is empty", path)
 	}
+	t.columns = make([]string, len(t.headers))
+	for i, h := range t.headers {
+		t.columns[i] = TagColumn(h)
+	}
+	return t, nil
+}
 
-	var assignments []TagAssignment
-	counter := make(map[string]int)
+// GenerateTags numbers the rows of inputPath through the project's tag
+// registry and writes the tags to outputPath as YAML. The input is a CSV
+// file of category, subcat and name columns, plus any others the tag
+// format uses, or a YAML file in the nested format below.
+//
+// Rows are identified by category, subcat and name, so a name must not
+// repeat within a category and subcat. When the CSV has a parent column,
+// rows naming a parent are instruments of that equipment: they take its
+// category (the system code), system and number block, and the output is
+// nested System → Equipment → Instrument instead of a flat list. Each
+// piece of equipment gets the next block in its system.
+func GenerateTags(inputPath, outputPath string) (TagReport, error) {
+	var report TagReport
+	if config.TaggingFormat == "" {
+		return report, fmt.Errorf("missing tag format in env")
+	}
+	format, err := ParseTagFormat(config.TaggingFormat)
+	if err != nil {
+		return report, err
+	}
 
-	for i, row := range records {
-		if i == 0 {
+	var table tagTable
+	switch strings.ToLower(filepath.Ext(inputPath)) {
+	case ".yaml", ".yml":
+		table, err = readTagTree(inputPath)
+	default:
+		table, err = readTagCSV(inputPath)
+	}
+	if err != nil {
+		return report, err
+	}
+	// Tokens in optional segments may name columns this file doesn't have;
+	// the segment is then left out.
+	for _, token := range format.requiredTokens() {
+		switch token {
+		case "category", "subcat", "name", "id":
 			continue
 		}
+		if !table.has(token) {
+			return report, fmt.Errorf("tag format %q uses {%s}, but %s has no such column (columns: %s)",
+				format, token, inputPath, strings.Join(table.columns, ", "))
+		}
+	}
+
+	// Function or product columns turn on IEC 81346 designations,
+	// checked against the project's class table.
+	var classes ClassTable
+	withDesignations := slices.ContainsFunc(designationColumns, table.has)
+	if withDesignations {
+		if classes, err = LoadClassTable(outputPath); err != nil {
+			return report, err
+		}
+	}
+
+	report.Registry = TagRegistryPath(outputPath)
+	release, err := acquireLock(report.Registry + ".lock")
+	if err != nil {
+		return report, err
+	}
+	defer release()
+	registry, err := LoadTagRegistry(report.Registry)
+	if err != nil {
+		return report, err
+	}
+	source := registrySource(report.Registry, inputPath)
+
+	// Equipment goes first so its instruments can take its number.
+	nested := table.has("parent")
+	order := make([]int, 0, len(table.rows))
+	for pass := 0; pass < 2; pass++ {
+		for i, row := range table.rows {
+			if (table.cell(row, "parent") == "") == (pass == 0) {
+				order = append(order, i)
+			}
+		}
+	}
+
+	tags := make([]string, len(table.rows))
+	designations := make([]Designation, len(table.rows))
+	rows := make([]TagRow, len(table.rows))
+	values := make([]map[string]string, len(table.rows))
+	equipment := map[string]int{}
+	number := map[int]int{}
+	seen := map[int]bool{}
+	// used maps each rendered tag to the line that got it.
+	used := map[string]int{}
+
+	for _, i := range order {
+		row := table.rows[i]
+		where := fmt.Sprintf("%s line %d", inputPath, table.lines[i])
 		if len(row) < 3 {
 			continue
 		}
@@ -54,32 +205,129 @@ func GenerateTags(csvPath, outputPath string) error {
 			Category: strings.TrimSpace(row[0]),
 			Subcat:   strings.TrimSpace(row[1]),
 			Name:     strings.TrimSpace(row[2]),
+			Parent:   table.cell(row, "parent"),
+		}
+		if r.Name == "" {
+			return report, fmt.Errorf("%s: a tag needs a name to be registered", where)
+		}
+		v := map[string]string{}
+		for c, cell := range row {
+			if c < len(table.columns) {
+				v[table.columns[c]] = strings.TrimSpace(cell)
+			}
 		}
 
-		key := r.Category + "-" + r.Subcat
-		counter[key]++
-		id := config.TaggingStart + counter[key] - 1
+		block := noBlock
+		if r.Parent != "" {
+			p, ok := equipment[r.Parent]
+			if !ok {
+				return report, fmt.Errorf("%s: parent %q is not an equipment row (one without a parent)", where, r.Parent)
+			}
+			if r.Category == "" {
+				r.Category = rows[p].Category
+			} else if r.Category != rows[p].Category {
+				return report, fmt.Errorf("%s: %s is in system %s but its parent %s is in %s", where, r.Name, r.Category, r.Parent, rows[p].Category)
+			}
+			for _, inherit := range []string{"system", "line", "location"} {
+				if v[inherit] == "" {
+					v[inherit] = values[p][inherit]
+				}
+			}
+			block = number[p]
+		} else if nested {
+			if _, dup := equipment[r.Name]; dup {
+				return report, fmt.Errorf("%s: equipment %q appears twice", where, r.Name)
+			}
+			equipment[r.Name] = i
+		}
 
-		tagID := strings.ReplaceAll(config.TaggingFormat, "{category}", r.Category)
-		tagID = strings.ReplaceAll(tagID, "{subcat}", r.Subcat)
-		tagID = strings.ReplaceAll(tagID, "{id}", fmt.Sprintf("%02d", id))
+		// Equipment in a nested file is numbered through its whole system,
+		// giving each one its own block; flat tags count per subcat.
+		group := func(t RegisteredTag) bool { return t.Category == r.Category && t.Subcat == r.Subcat }
+		if nested {
+			group = func(t RegisteredTag) bool { return t.Category == r.Category && t.Parent == "" }
+		}
+		n, added := registry.assign(r, source, config.TaggingStart, block, group)
+		if seen[n] && r.Parent != "" {
+			return report, fmt.Errorf("%s: %q appears twice under %s", where, r.Name, r.Parent)
+		}
+		if seen[n] {
+			return report, fmt.Errorf("%s: %q appears twice in %s-%s", where, r.Name, r.Category, r.Subcat)
+		}
+		seen[n] = true
+		if added {
+			report.New++
+		}
 
-		assignments = append(assignments, TagAssignment{
-			ID:       tagID,
-			Category: r.Category,
-			Subcat:   r.Subcat,
-			Name:     r.Name,
-		})
+		v["category"], v["subcat"], v["name"] = r.Category, r.Subcat, r.Name
+		v["id"] = strconv.Itoa(registry.Tags[n].Number)
+		tag, err := format.Render(v)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", where, err)
+		}
+		if line, dup := used[tag]; dup {
+			return report, fmt.Errorf("%s: %q would be tagged %s, which line %d already is", where, r.Name, tag, line)
+		}
+		used[tag] = table.lines[i]
+		registry.Tags[n].Tag = tag
+		if withDesignations {
+			d, err := tagDesignation(v, format.renderToken("id", v["id"]))
+			if err == nil {
+				err = d.Validate(classes)
+			}
+			if err != nil {
+				return report, fmt.Errorf("%s: %w", where, err)
+			}
+			designations[i] = d
+			v["designation"] = d.Format(config.DesignationForm)
+		}
+		tags[i], rows[i], values[i], number[i] = tag, r, v, registry.Tags[n].Number
+		report.Tags++
 	}
+	report.Retired = registry.retire(source, seen)
 
-	outData, err := yaml.Marshal(assignments)
+	var out any
+	if nested {
+		if out, err = buildTagTree(table, rows, values, tags); err != nil {
+			return report, err
+		}
+	} else {
+		var assignments []TagAssignment
+		for i, r := range rows {
+			if tags[i] == "" {
+				continue
+			}
+			a := TagAssignment{ID: tags[i], Category: r.Category, Subcat: r.Subcat, Name: r.Name}
+			if withDesignations {
+				d := designations[i]
+				a.Function = d.AspectString(AspectFunction)
+				a.Product = d.AspectString(AspectProduct)
+				a.Location = d.AspectString(AspectLocation)
+				a.Designation = values[i]["designation"]
+			}
+			assignments = append(assignments, a)
+		}
+		out = assignments
+	}
+	outData, err := yaml.Marshal(out)
 	if err != nil {
-		return fmt.Errorf("marshal yaml: %w", err)
+		return report, fmt.Errorf("marshal yaml: %w", err)
 	}
 
+	// Pin the numbers before anything can print the tags.
+	if err := registry.save(report.Registry); err != nil {
+		return report, fmt.Errorf("write tag registry: %w", err)
+	}
 	if err := os.WriteFile(outputPath, outData, 0644); err != nil {
-		return fmt.Errorf("write output: %w", err)
+		return report, fmt.Errorf("write output: %w", err)
 	}
 
-	return nil
+	if root, ok := FindProjectRoot(outputPath); ok {
+		return report, AppendJournal(root, JournalEntry{
+			Action: ActionTags,
+			Note: fmt.Sprintf("generated %d tags (%d new, %d retired) from %s into %s",
+				report.Tags, report.New, report.Retired, inputPath, outputPath),
+		})
+	}
+	return report, nil
 }
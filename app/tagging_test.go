@@ -0,0 +1,193 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// useTagConfig points config at a fresh base directory with format for
+// the length of the test.
+func useTagConfig(t *testing.T, format string) string {
+	t.Helper()
+	saved := config
+	t.Cleanup(func() { config = saved })
+	dir := t.TempDir()
+	config = defaultConfig
+	config.BaseDir = dir
+	config.TaggingFormat = format
+	return dir
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAssignments(t *testing.T, path string) []TagAssignment {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []TagAssignment
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestGenerateTagsLocationOnly(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}[+{location}]")
+	in, out := filepath.Join(dir, "tags.csv"), filepath.Join(dir, "tags.yaml")
+	writeFile(t, in, "Category,Subcat,Name,Location\nCAT,PMP,Pump 1,Building 2\nCAT,PMP,Pump 2,B2\n")
+
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatalf("GenerateTags: %v", err)
+	}
+	got := readAssignments(t, out)
+	if len(got) != 2 || got[0].ID != "CAT-PMP-01+Building 2" || got[1].ID != "CAT-PMP-02+B2" {
+		t.Fatalf("tags = %+v", got)
+	}
+	for _, a := range got {
+		if a.Designation != "" {
+			t.Errorf("%s has designation %q without function or product columns", a.ID, a.Designation)
+		}
+	}
+}
+
+func TestGenerateTagsDesignations(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}")
+	in, out := filepath.Join(dir, "tags.csv"), filepath.Join(dir, "tags.yaml")
+	writeFile(t, in, "Category,Subcat,Name,Product,Location\nCAT,PMP,Pump 1,M,LINE4\nCAT,TT,Temp 1,,\n")
+
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatalf("GenerateTags: %v", err)
+	}
+	got := readAssignments(t, out)
+	want := []string{"=CAT-M01+LINE4", "=CAT"}
+	for i, a := range got {
+		if a.Designation != want[i] {
+			t.Errorf("%s: designation = %q, want %q", a.ID, a.Designation, want[i])
+		}
+	}
+
+	writeFile(t, in, "Category,Subcat,Name,Product\nCAT,PMP,Pump 1,PMP\n")
+	if _, err := GenerateTags(in, out); err == nil || !strings.Contains(err.Error(), `unknown product class "PMP"`) {
+		t.Errorf("PMP against the default classes: err = %v", err)
+	}
+}
+
+func TestGenerateTagsRetiresOnlyOwnSource(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}")
+	a, b := filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")
+	writeFile(t, a, "Category,Subcat,Name\nCAT,PMP,Pump 1\n")
+	writeFile(t, b, "Category,Subcat,Name\nELE,MTR,Motor 1\n")
+
+	for _, in := range []string{a, b, a} {
+		report, err := GenerateTags(in, strings.TrimSuffix(in, ".csv")+".yaml")
+		if err != nil {
+			t.Fatalf("GenerateTags(%s): %v", in, err)
+		}
+		if report.Retired != 0 {
+			t.Errorf("GenerateTags(%s) retired %d tags", filepath.Base(in), report.Retired)
+		}
+	}
+
+	writeFile(t, a, "Category,Subcat,Name\n")
+	if report, err := GenerateTags(a, filepath.Join(dir, "a.yaml")); err != nil || report.Retired != 1 {
+		t.Fatalf("emptied a.csv: retired %d, err %v; want 1", report.Retired, err)
+	}
+	registry, err := LoadTagRegistry(filepath.Join(dir, TagRegistryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range registry.Tags {
+		if retired := tag.Retired != ""; retired != (tag.Source == "a.csv") {
+			t.Errorf("%s from %s: retired = %v", tag.Tag, tag.Source, retired)
+		}
+	}
+}
+
+func TestGenerateTagsInstrumentBlocks(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}")
+	config.TaggingStart = 0
+	in, out := filepath.Join(dir, "tags.csv"), filepath.Join(dir, "tags.yaml")
+	writeFile(t, in, "Category,Subcat,Name,Parent\nCAT,PMP,Pump 0,\n,PT,Suction PT,Pump 0\nCAT,PMP,Pump 1,\n,PT,Suction PT,Pump 1\n")
+
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatalf("GenerateTags: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"CAT-PMP-00", "CAT-PT-00", "CAT-PMP-01", "CAT-PT-01"} {
+		if !strings.Contains(string(data), tag) {
+			t.Errorf("output lacks %s:\n%s", tag, data)
+		}
+	}
+
+	writeFile(t, in, "Category,Subcat,Name,Parent\nCAT,PMP,Pump 1,\n,PT,Suction PT,Pump 1\n,PT,Discharge PT,Pump 1\n")
+	if _, err := GenerateTags(in, out); err == nil || !strings.Contains(err.Error(), "CAT-PT-") {
+		t.Errorf("two PTs under one pump: err = %v, want a duplicate tag error", err)
+	}
+}
+
+func TestGenerateTagsKeepsNumbers(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}")
+	in, out := filepath.Join(dir, "tags.csv"), filepath.Join(dir, "tags.yaml")
+	writeFile(t, in, "Category,Subcat,Name\nCAT,PMP,Pump A\nCAT,PMP,Pump B\nCAT,PMP,Pump C\n")
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatal(err)
+	}
+
+	// A row inserted in the middle gets the next number; the rest keep theirs.
+	writeFile(t, in, "Category,Subcat,Name\nCAT,PMP,Pump A\nCAT,PMP,Pump New\nCAT,PMP,Pump B\nCAT,PMP,Pump C\n")
+	report, err := GenerateTags(in, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.New != 1 {
+		t.Errorf("New = %d, want 1", report.New)
+	}
+	want := map[string]string{"Pump A": "CAT-PMP-01", "Pump New": "CAT-PMP-04", "Pump B": "CAT-PMP-02", "Pump C": "CAT-PMP-03"}
+	for _, a := range readAssignments(t, out) {
+		if a.ID != want[a.Name] {
+			t.Errorf("%s tagged %s, want %s", a.Name, a.ID, want[a.Name])
+		}
+	}
+
+	// A removed row's number is not handed out again.
+	writeFile(t, in, "Category,Subcat,Name\nCAT,PMP,Pump A\nCAT,PMP,Pump B\nCAT,PMP,Pump New\nCAT,PMP,Pump D\n")
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range readAssignments(t, out) {
+		if a.Name == "Pump D" && a.ID != "CAT-PMP-05" {
+			t.Errorf("Pump D tagged %s, want CAT-PMP-05 after Pump C retired", a.ID)
+		}
+	}
+}
+
+func TestGenerateTagsOptionalColumns(t *testing.T) {
+	dir := useTagConfig(t, "{category}-{subcat}-{id}[-{redundancy}]")
+	in, out := filepath.Join(dir, "tags.csv"), filepath.Join(dir, "tags.yaml")
+	writeFile(t, in, "Category,Subcat,Name\nCAT,PMP,Pump 1\n")
+	if _, err := GenerateTags(in, out); err != nil {
+		t.Fatalf("CSV without the optional column: %v", err)
+	}
+	if got := readAssignments(t, out); len(got) != 1 || got[0].ID != "CAT-PMP-01" {
+		t.Errorf("tags = %+v", got)
+	}
+
+	config.TaggingFormat = "{system}-{id}"
+	if _, err := GenerateTags(in, out); err == nil || !strings.Contains(err.Error(), "no such column") {
+		t.Errorf("required column missing: err = %v", err)
+	}
+}
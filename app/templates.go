@@ -0,0 +1,168 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// PresetFile is a starter file copied into every project built from a
+// preset. Template is relative to Config/Presets/<preset>/ and Path to the
+// project folder. Both the path and the contents are rendered as Go
+// templates with TemplateData unless Raw is set.
+type PresetFile struct {
+	Path     string `yaml:"path"`
+	Template string `yaml:"template"`
+	Raw      bool   `yaml:"raw,omitempty"`
+	// From names the preset that supplied the file; it is filled in when
+	// presets are loaded so a resolved preset shows where each file came from.
+	From string `yaml:"from,omitempty"`
+
+	// source is the resolved template file, set when the preset is loaded.
+	source string
+}
+
+// TemplateData is what preset folder names, file paths and file contents
+// can refer to, e.g. {{.ID}}, {{.Fields.customer}} or {{.Vars.customer}}.
+type TemplateData struct {
+	ID          string
+	Name        string
+	Description string
+	Status      string
+	Tags        []string
+	CreatedAt   string
+	Preset      string
+	Fields      map[string]string
+	Vars        map[string]string
+}
+
+var templateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+}
+
+// newTemplateData merges the preset's vars with the ones given for this
+// project, which win.
+func newTemplateData(p Project, preset Preset, vars map[string]string) TemplateData {
+	merged := map[string]string{}
+	for k, v := range preset.Vars {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+	return TemplateData{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Status:      p.Status,
+		Tags:        p.Tags,
+		CreatedAt:   p.CreatedAt,
+		Preset:      p.Preset,
+		Fields:      p.Fields,
+		Vars:        merged,
+	}
+}
+
+// renderString renders a short template such as a folder name or path.
+func renderString(name, text string, data TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// resolveFiles points each file at its template under dir and checks that
+// neither side escapes its folder.
+func resolveFiles(files []PresetFile, dir string) error {
+	for i := range files {
+		f := &files[i]
+		if f.Path == "" || f.Template == "" {
+			return fmt.Errorf("file entries need both path and template")
+		}
+		if !insideFolder(f.Path) {
+			return fmt.Errorf("file %q must stay inside the project", f.Path)
+		}
+		if !insideFolder(f.Template) {
+			return fmt.Errorf("template %q must stay inside %s", f.Template, dir)
+		}
+		f.source = filepath.Join(dir, filepath.FromSlash(f.Template))
+	}
+	return nil
+}
+
+func insideFolder(path string) bool {
+	path = filepath.ToSlash(strings.TrimSpace(path))
+	return path != "" && !filepath.IsAbs(path) && !strings.HasPrefix(path, "/") &&
+		!slices.Contains(strings.Split(path, "/"), "..")
+}
+
+// renderPath renders a file's destination, relative to the project folder.
+func renderPath(f PresetFile, data TemplateData) (string, error) {
+	rel, err := renderString(f.Path, f.Path, data)
+	if err != nil {
+		return "", fmt.Errorf("render path %s: %w", f.Path, err)
+	}
+	if !insideFolder(rel) {
+		return "", fmt.Errorf("file %q renders outside the project", f.Path)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// renderFiles writes a preset's starter files into the project. Files that
+// already exist are left alone and reported as skipped.
+func renderFiles(root string, files []PresetFile, data TemplateData) (skipped []string, err error) {
+	for _, f := range files {
+		rel, err := renderPath(f, data)
+		if err != nil {
+			return skipped, err
+		}
+		dest := filepath.Join(root, filepath.FromSlash(rel))
+
+		if _, err := os.Stat(dest); err == nil {
+			skipped = append(skipped, rel)
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return skipped, err
+		}
+
+		content, err := os.ReadFile(f.source)
+		if err != nil {
+			return skipped, fmt.Errorf("read template %s: %w", f.Template, err)
+		}
+		if !f.Raw {
+			t, err := template.New(f.Template).Funcs(templateFuncs).Option("missingkey=error").Parse(string(content))
+			if err != nil {
+				return skipped, fmt.Errorf("parse template %s: %w", f.Template, err)
+			}
+			var b bytes.Buffer
+			if err := t.Execute(&b, data); err != nil {
+				return skipped, fmt.Errorf("render template %s: %w", f.Template, err)
+			}
+			content = b.Bytes()
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return skipped, err
+		}
+		if err := os.WriteFile(dest, content, 0644); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
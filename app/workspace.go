@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DefaultWorkspaceName stands for PROJMAN_BASE_DIR itself, used when no
+// workspace is selected.
+const DefaultWorkspaceName = "default"
+
+// A Workspace is a named project root with its own presets and tagging
+// format. It is defined by PROJMAN_WORKSPACES=name,... and keys such as
+// PROJMAN_WORKSPACE_<NAME>_BASE_DIR, and selected with PROJMAN_WORKSPACE or
+// the -workspace flag.
+type Workspace struct {
+	Name          string
+	BaseDir       string
+	FolderPresets []string
+	TaggingFormat string
+	DefaultPreset string
+}
+
+// workspaceSettings maps each per-workspace key suffix to the setting it
+// overrides while the workspace is active.
+var workspaceSettings = []struct{ suffix, key string }{
+	{"_BASE_DIR", "PROJMAN_BASE_DIR"},
+	{"_PRESETS", "PROJMAN_PROJECT_FOLDER_PRESETS"},
+	{"_TAGGING_FORMAT", "PROJMAN_TAGGING_FORMAT"},
+	{"_DEFAULT_PRESET", "PROJMAN_DEFAULT_PRESET"},
+}
+
+// extraOrigins holds where the keys outside settings came from: the
+// workspace keys and the custom field schema.
+var extraOrigins = map[string]ConfigValue{}
+
+// WorkspaceKey is the config key for one of a workspace's settings, e.g.
+// WorkspaceKey("field", "_BASE_DIR") is PROJMAN_WORKSPACE_FIELD_BASE_DIR.
+func WorkspaceKey(name, suffix string) string {
+	upper := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	return "PROJMAN_WORKSPACE_" + upper + suffix
+}
+
+func isWorkspaceKey(key string) bool {
+	if key == "PROJMAN_WORKSPACE" || key == "PROJMAN_WORKSPACES" {
+		return true
+	}
+	if !strings.HasPrefix(key, "PROJMAN_WORKSPACE_") {
+		return false
+	}
+	for _, s := range workspaceSettings {
+		if strings.HasSuffix(key, s.suffix) && len(key) > len("PROJMAN_WORKSPACE_")+len(s.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastValue returns the last non-empty value of key across layers.
+func lastValue(layers []configLayer, key string) (string, ConfigValue) {
+	value, origin := "", ConfigValue{Key: key, Origin: OriginDefault}
+	for _, l := range layers {
+		if v := l.values[key]; v != "" {
+			value, origin = v, ConfigValue{Key: key, Origin: l.origin, Path: l.path}
+		}
+	}
+	return value, origin
+}
+
+// resolveWorkspaces reads the workspace definitions from layers and records
+// their origins in found.
+func resolveWorkspaces(layers []configLayer, found map[string]ConfigValue) ([]Workspace, error) {
+	names, origin := lastValue(layers, "PROJMAN_WORKSPACES")
+	found[origin.Key] = origin
+
+	var workspaces []Workspace
+	for _, name := range CleanTags(names) {
+		if name == DefaultWorkspaceName {
+			return nil, fmt.Errorf("%w: %q is reserved for PROJMAN_BASE_DIR and cannot name a workspace", ErrInvalidConfig, name)
+		}
+		w := Workspace{Name: name}
+		for _, s := range workspaceSettings {
+			key := WorkspaceKey(name, s.suffix)
+			v, origin := lastValue(layers, key)
+			found[key] = origin
+			switch s.suffix {
+			case "_BASE_DIR":
+				w.BaseDir = ExpandHome(v)
+			case "_PRESETS":
+				w.FolderPresets = CleanTags(v)
+			case "_TAGGING_FORMAT":
+				w.TaggingFormat = v
+			case "_DEFAULT_PRESET":
+				w.DefaultPreset = v
+			}
+		}
+		if w.BaseDir == "" {
+			return nil, fmt.Errorf("%w: workspace %s needs %s", ErrInvalidConfig, name, WorkspaceKey(name, "_BASE_DIR"))
+		}
+		workspaces = append(workspaces, w)
+	}
+	return workspaces, nil
+}
+
+// layer turns the workspace's own settings into a config layer that sits
+// above the local file.
+func (w Workspace) layer() configLayer {
+	values := map[string]string{
+		"PROJMAN_BASE_DIR":               w.BaseDir,
+		"PROJMAN_PROJECT_FOLDER_PRESETS": strings.Join(w.FolderPresets, ","),
+		"PROJMAN_TAGGING_FORMAT":         w.TaggingFormat,
+		"PROJMAN_DEFAULT_PRESET":         w.DefaultPreset,
+	}
+	return configLayer{origin: OriginWorkspace, path: w.Name, values: values}
+}
+
+func findWorkspace(workspaces []Workspace, name string) (Workspace, error) {
+	for _, w := range workspaces {
+		if w.Name == name {
+			return w, nil
+		}
+	}
+	names := []string{DefaultWorkspaceName}
+	for _, w := range workspaces {
+		names = append(names, w.Name)
+	}
+	return Workspace{}, fmt.Errorf("%w: unknown workspace %q (want one of %s)", ErrInvalidConfig, name, strings.Join(names, ", "))
+}
+
+// WorkspaceNames lists the default workspace followed by the configured ones.
+func WorkspaceNames() []string {
+	names := []string{DefaultWorkspaceName}
+	for _, w := range config.Workspaces {
+		names = append(names, w.Name)
+	}
+	return names
+}
+
+// SwitchWorkspace reloads the configuration with another workspace active,
+// as if it had been given with -workspace.
+func SwitchWorkspace(name string) (Config, error) {
+	flags := maps.Clone(loadedFlags)
+	if flags == nil {
+		flags = map[string]string{}
+	}
+	flags["PROJMAN_WORKSPACE"] = name
+	return LoadConfig(flags)
+}
+
+// ListAllWorkspaces lists the projects of the default workspace and every
+// configured one, each tagged with its workspace. Workspaces sharing a base
+// directory are only listed once. A workspace that can't be read, such as
+// an unmounted share, is reported as a warning and the rest are still
+// listed; it is only an error when none can be read.
+func ListAllWorkspaces() ([]Project, []string, error) {
+	roots := []Workspace{{Name: DefaultWorkspaceName, BaseDir: defaultBaseDir}}
+	roots = append(roots, config.Workspaces...)
+
+	var all []Project
+	var warnings []string
+	var seen []string
+	failed := 0
+	for _, w := range roots {
+		if slices.Contains(seen, w.BaseDir) {
+			continue
+		}
+		seen = append(seen, w.BaseDir)
+		projects, skipped, err := ListProjects(w.BaseDir)
+		for _, s := range skipped {
+			warnings = append(warnings, fmt.Sprintf("workspace %s: %s", w.Name, s))
+		}
+		if err != nil {
+			failed++
+			warnings = append(warnings, fmt.Sprintf("skipping workspace %s: %v", w.Name, err))
+			continue
+		}
+		for _, p := range projects {
+			p.Workspace = w.Name
+			all = append(all, p)
+		}
+	}
+	if failed == len(seen) {
+		return all, warnings, fmt.Errorf("none of the %d workspaces could be read", failed)
+	}
+	return all, warnings, nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListAllWorkspacesSkipsUnreachable(t *testing.T) {
+	saved, savedBase := config, defaultBaseDir
+	t.Cleanup(func() { config, defaultBaseDir = saved, savedBase })
+	home, site := t.TempDir(), t.TempDir()
+	config = defaultConfig
+	defaultBaseDir = home
+	config.Workspaces = []Workspace{
+		{Name: "gone", BaseDir: filepath.Join(site, "unmounted")},
+		{Name: "site", BaseDir: site},
+	}
+	if _, err := CreateProject(site, Params{ID: "S-1", Name: "on site"}); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, warnings, err := ListAllWorkspaces()
+	if err != nil {
+		t.Fatalf("ListAllWorkspaces: %v", err)
+	}
+	if len(projects) != 1 || projects[0].ID != "S-1" || projects[0].Workspace != "site" {
+		t.Errorf("projects = %+v", projects)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "gone") {
+		t.Errorf("warnings = %q, want one about workspace gone", warnings)
+	}
+
+	config.Workspaces = config.Workspaces[:1]
+	defaultBaseDir = filepath.Join(home, "missing")
+	if _, _, err := ListAllWorkspaces(); err == nil {
+		t.Error("no readable workspace: want an error")
+	}
+}
@@ -0,0 +1,562 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thornzero/projman/app"
+	"github.com/thornzero/projman/ui"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands map[string]command
+
+// cfg is the configuration resolved by Run before any command runs.
+var cfg app.Config
+
+func init() {
+	commands = map[string]command{
+		"migrate": {"migrate [-dry-run]", runMigrate},
+		"new":     {"new (-id=ID | -auto-id) -name=NAME [-desc=TEXT] [-status=STATUS] [-tags=a,b] [-preset=NAME] [-var key=value]... [-field name=value]...", runNew},
+		"update":  {"update -id=ID [-name=NAME] [-desc=TEXT] [-status=STATUS] [-tags=a,b] [-add-tags=a] [-remove-tags=b] [-field name=value]...", runUpdate},
+		"log":     {"log -id=ID [-format=table|json]", runLog},
+		"list":    {"list [-all-workspaces] [-format=table|json|yaml|csv|tsv] [-fields=id,name,...]", runList},
+		"search":  {"search QUERY [-format=table|json|yaml|csv|tsv] [-fields=id,name,...]", runSearch},
+		"reindex": {"reindex", runReindex},
+		"status":  {"status -id=ID [-format=table|json|yaml|csv|tsv] [-fields=id,name,...]", runStatus},
+		"open":    {"open -id=ID", runOpen},
+		"preset":  {"preset list | preset show NAME | preset apply ID [PRESET] [-check] [-var key=value]... | preset capture ID NAME [-files=a,b] [-ignore=a,b] [-max-size=BYTES] [-force]", runPreset},
+		"archive": {"archive -id=ID [-remove]", runArchive},
+		"config":  {"config show [-origin]", runConfig},
+		"init":    {"init", runInit},
+		"restore": {"restore -id=ID [-status=STATUS]", runRestore},
+		"tags":    {"tags lint FILE [-rules=FILE] | tags designation DESIGNATION... [-long|-short] [-rules=FILE]", runTags},
+	}
+}
+
+// Run dispatches a projman subcommand and returns the process exit code.
+// Global flags come before the command; without a command the interactive
+// menu starts.
+func Run(args []string) int {
+	global := flag.NewFlagSet("projman", flag.ContinueOnError)
+	global.Usage = printUsage
+	base := global.String("base-dir", "", "base directory holding the projects")
+	workspace := global.String("workspace", "", "named workspace to work in")
+	overrides := map[string]string{}
+	global.Func("set", "override a setting as KEY=value, e.g. -set TAGGING_START=100 (repeatable)", setting(overrides))
+	if err := global.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *base != "" {
+		overrides["PROJMAN_BASE_DIR"] = *base
+	}
+	if *workspace != "" {
+		overrides["PROJMAN_WORKSPACE"] = *workspace
+	}
+	args = global.Args()
+
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		printUsage()
+		return 0
+	}
+
+	var err error
+	if cfg, err = app.LoadConfig(overrides); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if len(args) == 0 {
+		if err := ui.Tui(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "❌ Unknown command %q\n\n", args[0])
+		printUsage()
+		return 2
+	}
+
+	if err := cmd.run(args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: projman [-workspace=NAME] [-base-dir=DIR] [-set KEY=value]... <command> [flags]")
+	fmt.Println("\nRun without a command to start the interactive menu.")
+	fmt.Println("\nCommands:")
+	for _, name := range names {
+		fmt.Printf("  projman %s\n", commands[name].usage)
+	}
+}
+
+// parseArgs parses flags and positionals in any order, so both
+// "status -id=CP-1" and "status CP-1 -format=json" work.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// projectID returns the -id flag, falling back to the first positional argument.
+func projectID(flagID string, positional []string) (string, error) {
+	id := flagID
+	if id == "" && len(positional) > 0 {
+		id = positional[0]
+	}
+	id = app.ValidateID(id)
+	if id == "" {
+		return "", fmt.Errorf("a project ID is required")
+	}
+	return id, nil
+}
+
+// keyValue collects repeated key=value flags into m.
+func keyValue(m map[string]string) func(string) error {
+	return func(s string) error {
+		k, v, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return fmt.Errorf("%q must look like key=value", s)
+		}
+		m[strings.TrimSpace(k)] = v
+		return nil
+	}
+}
+
+// setting collects repeated -set KEY=value flags into m. The PROJMAN_
+// prefix is optional.
+func setting(m map[string]string) func(string) error {
+	return func(s string) error {
+		k, v, ok := strings.Cut(s, "=")
+		k = strings.ToUpper(strings.TrimSpace(k))
+		if !strings.HasPrefix(k, "PROJMAN_") {
+			k = "PROJMAN_" + k
+		}
+		if !ok || !app.IsConfigKey(k) {
+			return fmt.Errorf("%q must look like KEY=value with a known setting; see projman config show", s)
+		}
+		m[k] = v
+		return nil
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: projman %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// baseDir is the configured base directory, shared with the TUI.
+func baseDir() string {
+	return cfg.BaseDir
+}
+
+// warn prints warnings to stderr so they never mix with listed output.
+func warn(warnings []string) {
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "⚠️ %s\n", w)
+	}
+}
+
+func runNew(args []string) error {
+	fs := newFlagSet("new")
+	id := fs.String("id", "", "project ID")
+	name := fs.String("name", "", "project name")
+	desc := fs.String("desc", "", "project description")
+	status := fs.String("status", "", "initial project status (defaults to PROJMAN_DEFAULT_STATUS)")
+	tags := fs.String("tags", "", "comma-separated tags")
+	preset := fs.String("preset", "", "folder preset (defaults to PROJMAN_DEFAULT_PRESET)")
+	vars := map[string]string{}
+	fs.Func("var", "template variable for preset files as key=value (repeatable)", keyValue(vars))
+	fields := map[string]string{}
+	fs.Func("field", "custom field value as name=value (repeatable)", keyValue(fields))
+	autoID := fs.Bool("auto-id", false, "allocate the next ID from PROJMAN_PROJECT_ID_PATTERN")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var pid string
+	if *autoID {
+		if *id != "" || len(positional) > 0 {
+			return fmt.Errorf("-auto-id can't be combined with an ID")
+		}
+	} else if pid, err = projectID(*id, positional); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	base := baseDir()
+	p, err := app.CreateProject(base, app.Params{
+		ID:          pid,
+		Name:        *name,
+		Description: *desc,
+		Status:      *status,
+		Tags:        *tags,
+		Preset:      *preset,
+		Vars:        vars,
+		Fields:      fields,
+		AutoID:      *autoID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Created project %s at %s\n", p.ID, p.Path)
+	return nil
+}
+
+func runUpdate(args []string) error {
+	fs := newFlagSet("update")
+	id := fs.String("id", "", "project ID")
+	name := fs.String("name", "", "new project name")
+	desc := fs.String("desc", "", "new project description")
+	status := fs.String("status", "", "new project status")
+	tags := fs.String("tags", "", "replace tags with this comma-separated list")
+	addTags := fs.String("add-tags", "", "comma-separated tags to add")
+	removeTags := fs.String("remove-tags", "", "comma-separated tags to remove")
+	fields := map[string]string{}
+	fs.Func("field", "set a custom field as name=value; an empty value clears it (repeatable)", keyValue(fields))
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+
+	var c app.Changes
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			c.Name = name
+		case "desc":
+			c.Description = desc
+		case "status":
+			c.Status = status
+		case "tags":
+			t := app.CleanTags(*tags)
+			c.Tags = &t
+		case "add-tags":
+			c.AddTags = app.CleanTags(*addTags)
+		case "remove-tags":
+			c.RemoveTags = app.CleanTags(*removeTags)
+		case "field":
+			c.Fields = fields
+		}
+	})
+	if c.Name == nil && c.Description == nil && c.Status == nil && c.Tags == nil &&
+		len(c.AddTags) == 0 && len(c.RemoveTags) == 0 && c.Fields == nil {
+		return fmt.Errorf("nothing to update; pass -name, -desc, -status, -tags, -add-tags, -remove-tags or -field")
+	}
+
+	base := baseDir()
+	p, err := app.UpdateProject(base, pid, c)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated project %s\n", p.ID)
+	return nil
+}
+
+func runList(args []string) error {
+	fs := newFlagSet("list")
+	format, fields := outputFlags(fs)
+	all := fs.Bool("all-workspaces", false, "list the projects of every workspace")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	f, cols, err := parseOutput(*format, *fields)
+	if err != nil {
+		return err
+	}
+
+	if *all {
+		if len(cols) == 0 {
+			cols = app.ProjectFields
+			if f == app.FormatTable {
+				cols = app.ListFields
+			}
+			cols = append([]string{app.WorkspaceField}, cols...)
+		}
+		projects, warnings, err := app.ListAllWorkspaces()
+		warn(warnings)
+		if err != nil {
+			return err
+		}
+		return app.WriteProjects(os.Stdout, projects, f, cols)
+	}
+
+	base := baseDir()
+	projects, warnings, err := app.ListProjects(base)
+	warn(warnings)
+	if err != nil {
+		return err
+	}
+	return app.WriteProjects(os.Stdout, projects, f, cols)
+}
+
+func runSearch(args []string) error {
+	fs := newFlagSet("search")
+	format, fields := outputFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("a search query is required")
+	}
+	f, cols, err := parseOutput(*format, *fields)
+	if err != nil {
+		return err
+	}
+
+	base := baseDir()
+	projects, warnings, err := app.ListProjects(base)
+	warn(warnings)
+	if err != nil {
+		return err
+	}
+	found := app.SearchProjects(projects, strings.Join(positional, " "))
+	return app.WriteProjects(os.Stdout, found, f, cols)
+}
+
+func runReindex(args []string) error {
+	fs := newFlagSet("reindex")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	base := baseDir()
+	n, warnings, err := app.Reindex(base)
+	warn(warnings)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🗂️ Indexed %d projects in %s\n", n, base)
+	return nil
+}
+
+func runStatus(args []string) error {
+	fs := newFlagSet("status")
+	id := fs.String("id", "", "project ID")
+	format, fields := outputFlags(fs)
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	f, cols, err := parseOutput(*format, *fields)
+	if err != nil {
+		return err
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+	base := baseDir()
+	p, err := app.ReadProjectFile(base, pid)
+	if err != nil {
+		return err
+	}
+	return app.WriteProject(os.Stdout, p, f, cols)
+}
+
+func outputFlags(fs *flag.FlagSet) (format, fields *string) {
+	format = fs.String("format", "table", "output format: table, json, yaml, csv or tsv")
+	fields = fs.String("fields", "", "comma-separated columns, e.g. id,name,status")
+	return format, fields
+}
+
+func parseOutput(format, fields string) (app.Format, []string, error) {
+	f, err := app.ParseFormat(format)
+	if err != nil {
+		return "", nil, err
+	}
+	cols, err := app.ParseFields(fields)
+	return f, cols, err
+}
+
+func runOpen(args []string) error {
+	fs := newFlagSet("open")
+	id := fs.String("id", "", "project ID")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+	base := baseDir()
+	p, err := app.ReadProjectFile(base, pid)
+	if err != nil {
+		return err
+	}
+	return app.OpenFolder(p.Path)
+}
+
+func runArchive(args []string) error {
+	fs := newFlagSet("archive")
+	id := fs.String("id", "", "project ID")
+	remove := fs.Bool("remove", false, "delete the live project folder after the archive is verified")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+	base := baseDir()
+	if _, err := app.ArchiveProject(base, pid, app.ArchiveOptions{RemoveSource: *remove}); err != nil {
+		return err
+	}
+	fmt.Printf("📦 Archived project %s to %s\n", pid, app.ArchivePath(base, pid))
+	return nil
+}
+
+func runLog(args []string) error {
+	fs := newFlagSet("log")
+	id := fs.String("id", "", "project ID")
+	format := fs.String("format", "table", "output format: table or json")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	f, err := app.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	if f != app.FormatTable && f != app.FormatJSON {
+		return fmt.Errorf("unknown log format %q (want table or json)", *format)
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+	base := baseDir()
+	p, err := app.ReadProjectFile(base, pid)
+	if err != nil {
+		return err
+	}
+	entries, err := app.ReadJournal(filepath.Join(base, p.ID))
+	if err != nil {
+		return err
+	}
+	return app.WriteJournal(os.Stdout, entries, f)
+}
+
+// runInit runs the interactive setup wizard, which also starts on its own
+// the first time projman is launched without a command.
+func runInit(args []string) error {
+	if _, err := parseArgs(newFlagSet("init"), args); err != nil {
+		return err
+	}
+	return ui.Setup(cfg)
+}
+
+func runMigrate(args []string) error {
+	fs := newFlagSet("migrate")
+	dryRun := fs.Bool("dry-run", false, "report what would change without writing")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	base := baseDir()
+	results, err := app.MigrateProjects(base, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	pending, failed := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+			fmt.Printf("❌ %s: %v\n", r.ID, r.Err)
+		case len(r.Changes) > 0:
+			pending++
+			fmt.Printf("🔧 %s (schema v%d → v%d)\n", r.ID, r.From, app.CurrentSchemaVersion)
+			for _, c := range r.Changes {
+				fmt.Printf("     - %s\n", c)
+			}
+		}
+	}
+
+	verb := "Migrated"
+	if *dryRun {
+		verb = "Would migrate"
+	}
+	fmt.Printf("\n%s %d of %d projects to schema v%d.\n", verb, pending, len(results), app.CurrentSchemaVersion)
+	if failed > 0 {
+		return fmt.Errorf("%d projects could not be migrated", failed)
+	}
+	return nil
+}
+
+func runRestore(args []string) error {
+	fs := newFlagSet("restore")
+	id := fs.String("id", "", "project ID")
+	status := fs.String("status", "", "status to give the restored project (defaults to the first allowed after archived)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	pid, err := projectID(*id, positional)
+	if err != nil {
+		return err
+	}
+	base := baseDir()
+	p, err := app.RestoreProject(base, pid, *status)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📂 Restored project %s to %s\n", p.ID, p.Path)
+	return nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/thornzero/projman/app"
+)
+
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "show" {
+		return fmt.Errorf("usage: projman %s", commands["config"].usage)
+	}
+	fs := newFlagSet("config")
+	origin := fs.Bool("origin", false, "show which file, variable or flag set each value")
+	if _, err := parseArgs(fs, args[1:]); err != nil {
+		return err
+	}
+
+	values := app.ConfigValues()
+	if !*origin {
+		for _, v := range values {
+			fmt.Printf("%s=%q\n", v.Key, v.Value)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tORIGIN")
+	for _, v := range values {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, v.Value, v.Source())
+	}
+	return w.Flush()
+}
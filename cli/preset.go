@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/thornzero/projman/app"
+)
+
+// presetCommands are the subcommands of "projman preset".
+var presetCommands = map[string]func(args []string) error{
+	"apply":   runPresetApply,
+	"capture": runPresetCapture,
+	"list":    runPresetList,
+	"show":    runPresetShow,
+}
+
+func runPreset(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: projman %s", commands["preset"].usage)
+	}
+	run, ok := presetCommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown preset command %q; usage: projman %s", args[0], commands["preset"].usage)
+	}
+	return run(args[1:])
+}
+
+func runPresetList(args []string) error {
+	if _, err := parseArgs(newFlagSet("preset"), args); err != nil {
+		return err
+	}
+	base := baseDir()
+	names, err := app.GetAvailablePresets(base)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// runPresetShow prints a preset with its extends chain resolved, which is
+// exactly what new projects are built from.
+func runPresetShow(args []string) error {
+	positional, err := parseArgs(newFlagSet("preset"), args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("a preset name is required")
+	}
+
+	base := baseDir()
+	p, err := app.LoadPreset(base, positional[0])
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(p); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// runPresetApply brings an existing project in line with a preset. With
+// -check it only reports, and fails if anything is missing.
+func runPresetApply(args []string) error {
+	fs := newFlagSet("preset")
+	check := fs.Bool("check", false, "only report differences; exit non-zero if the project is out of sync")
+	vars := map[string]string{}
+	fs.Func("var", "template variable for preset files as key=value (repeatable)", keyValue(vars))
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	pid, err := projectID("", positional)
+	if err != nil {
+		return err
+	}
+	name := ""
+	if len(positional) > 1 {
+		name = positional[1]
+	}
+
+	base := baseDir()
+	r, err := app.ApplyPreset(base, pid, name, vars, *check)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🔍 %s against preset %s\n", r.ID, r.Preset)
+	missing := "➕ missing"
+	if r.Applied {
+		missing = "✅ created"
+	}
+	for _, f := range r.MissingFolders {
+		fmt.Printf("  %s folder %s/\n", missing, f)
+	}
+	for _, f := range r.MissingFiles {
+		fmt.Printf("  %s file %s\n", missing, f)
+	}
+	for _, f := range r.ExtraFolders {
+		fmt.Printf("  ⚠️ extra folder %s/ (left alone)\n", f)
+	}
+
+	switch {
+	case r.InSync():
+		fmt.Println("\n✅ Project matches the preset.")
+	case *check:
+		return fmt.Errorf("%s is missing %d folders and %d files from preset %s",
+			r.ID, len(r.MissingFolders), len(r.MissingFiles), r.Preset)
+	default:
+		fmt.Printf("\n✅ Created %d folders and %d files.\n", len(r.MissingFolders), len(r.MissingFiles))
+	}
+	return nil
+}
+
+// runPresetCapture saves an existing project's layout as a new preset.
+func runPresetCapture(args []string) error {
+	fs := newFlagSet("preset")
+	files := fs.String("files", "", "comma-separated globs of files to copy as templates, e.g. README.md,*.csv")
+	ignore := fs.String("ignore", "", "comma-separated globs to skip on top of the built-in ignore list")
+	maxSize := fs.Int64("max-size", app.DefaultCaptureMaxSize, "largest file, in bytes, copied as a template")
+	force := fs.Bool("force", false, "overwrite an existing preset with the same name")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	pid, err := projectID("", positional)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 2 {
+		return fmt.Errorf("a preset name is required")
+	}
+
+	base := baseDir()
+	r, err := app.CapturePreset(base, pid, positional[1], app.CaptureOptions{
+		Files:   app.CleanTags(*files),
+		Ignore:  app.CleanTags(*ignore),
+		MaxSize: *maxSize,
+		Force:   *force,
+	})
+	if err != nil {
+		return err
+	}
+	for _, f := range r.Skipped {
+		fmt.Printf("⚠️ Skipped %s: larger than %d bytes\n", f, *maxSize)
+	}
+	fmt.Printf("✅ Captured preset %s from %s (%d files) at %s\n", r.Preset.Name, pid, len(r.Preset.Files), r.Path)
+	return nil
+}
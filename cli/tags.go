@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/thornzero/projman/app"
+)
+
+// tagsCommands are the subcommands of "projman tags".
+var tagsCommands = map[string]func(args []string) error{
+	"designation": runTagsDesignation,
+	"lint":        runTagsLint,
+}
+
+func runTags(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: projman %s", commands["tags"].usage)
+	}
+	run, ok := tagsCommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown tags command %q; usage: projman %s", args[0], commands["tags"].usage)
+	}
+	return run(args[1:])
+}
+
+// runTagsLint checks a tag file against the project's tag rules and fails
+// when anything is wrong, so it can gate a CI job.
+func runTagsLint(args []string) error {
+	fs := newFlagSet("tags")
+	rulesPath := fs.String("rules", "", "tag rules file (defaults to the project's "+app.TagRulesFile+")")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("usage: projman %s", commands["tags"].usage)
+	}
+	file := positional[0]
+
+	var rules app.TagRules
+	source := *rulesPath
+	if source != "" {
+		rules, err = app.ReadTagRules(source)
+	} else {
+		rules, source, err = app.LoadTagRules(file)
+	}
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		source = "built-in rules (uniqueness only)"
+	}
+
+	r, err := app.LintTags(file, rules)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔍 %s against %s\n", file, source)
+	for _, issue := range r.Issues {
+		fmt.Printf("%s:%d: %s: %s\n", file, issue.Line, issue.Tag, issue.Message)
+	}
+	if len(r.Issues) > 0 {
+		return fmt.Errorf("%d problems in %d tags", len(r.Issues), r.Tags)
+	}
+	fmt.Printf("✅ %d tags OK\n", r.Tags)
+	return nil
+}
+
+// runTagsDesignation checks IEC 81346 designations against the class table
+// and prints each in the requested form, so it doubles as a converter.
+func runTagsDesignation(args []string) error {
+	fs := newFlagSet("tags")
+	long := fs.Bool("long", false, "print the long form (F=.../P=.../L=...)")
+	short := fs.Bool("short", false, "print the short form (=...-...+...)")
+	rulesPath := fs.String("rules", "", "tag rules file with the class table (defaults to the project's "+app.TagRulesFile+")")
+	flags, positional := splitDesignationArgs(fs, args)
+	if err := fs.Parse(flags); err != nil {
+		return err
+	}
+	if len(positional) == 0 || (*long && *short) {
+		return fmt.Errorf("usage: projman %s", commands["tags"].usage)
+	}
+
+	var classes app.ClassTable
+	if *rulesPath != "" {
+		rules, err := app.ReadTagRules(*rulesPath)
+		if err != nil {
+			return err
+		}
+		classes = rules.Classes
+	} else {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if classes, err = app.LoadClassTable(wd); err != nil {
+			return err
+		}
+	}
+
+	form := cfg.DesignationForm
+	switch {
+	case *long:
+		form = app.DesignationLong
+	case *short:
+		form = app.DesignationShort
+	}
+	failed := 0
+	for _, s := range positional {
+		d, err := app.ParseDesignation(s)
+		if err == nil {
+			err = d.Validate(classes)
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Println(d.Format(form))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d designations are invalid", failed, len(positional))
+	}
+	return nil
+}
+
+// splitDesignationArgs separates the flags fs knows from designations,
+// which may start with - themselves (-FM0101). Everything after "--" is a
+// designation, for one that looks like a flag.
+func splitDesignationArgs(fs *flag.FlagSet, args []string) (flags, designations []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return flags, append(designations, args[i+1:]...)
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(name)
+		if !strings.HasPrefix(arg, "-") || f == nil {
+			designations = append(designations, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		if _, isBool := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !isBool && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return flags, designations
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestSplitDesignationArgs(t *testing.T) {
+	tests := []struct {
+		args                []string
+		flags, designations []string
+	}{
+		{[]string{"-FM0101"}, nil, []string{"-FM0101"}},
+		{[]string{"-FM0101", "-long"}, []string{"-long"}, []string{"-FM0101"}},
+		{[]string{"=CAT-FM0101", "-rules", "r.yaml", "+LINE4"}, []string{"-rules", "r.yaml"}, []string{"=CAT-FM0101", "+LINE4"}},
+		{[]string{"--rules=r.yaml", "-QM1"}, []string{"--rules=r.yaml"}, []string{"-QM1"}},
+		{[]string{"-short", "--", "-long", "-rules"}, []string{"-short"}, []string{"-long", "-rules"}},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("tags", flag.ContinueOnError)
+		fs.Bool("long", false, "")
+		fs.Bool("short", false, "")
+		fs.String("rules", "", "")
+		flags, designations := splitDesignationArgs(fs, tt.args)
+		if !reflect.DeepEqual(flags, tt.flags) || !reflect.DeepEqual(designations, tt.designations) {
+			t.Errorf("splitDesignationArgs(%q) = %q, %q; want %q, %q", tt.args, flags, designations, tt.flags, tt.designations)
+		}
+	}
+}
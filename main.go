@@ -1,9 +1,11 @@
 package main
 
 import (
-	tui "github.com/thornzero/projman/ui"
+	"os"
+
+	"github.com/thornzero/projman/cli"
 )
 
 func main() {
-	tui.Tui()
+	os.Exit(cli.Run(os.Args[1:]))
 }
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+// applyPresetModel shows how a project differs from a preset and creates
+// whatever is missing on enter. Existing files and folders are never touched.
+type applyPresetModel struct {
+	project app.Project
+	preset  selector
+	report  app.PresetReport
+	message string
+}
+
+func newApplyPresetModel(p app.Project) applyPresetModel {
+	presets, err := app.GetAvailablePresets(baseDir)
+	if err != nil {
+		presets = []string{app.DefaultPresetName}
+	}
+	if p.Preset != "" && !slices.Contains(presets, p.Preset) {
+		presets = append([]string{p.Preset}, presets...)
+	}
+	m := applyPresetModel{project: p, preset: newSelector("Preset", presets, p.Preset)}
+	return m.check()
+}
+
+func (m applyPresetModel) check() applyPresetModel {
+	r, err := app.ApplyPreset(baseDir, m.project.ID, m.preset.value(), nil, true)
+	m.report, m.message = r, ""
+	if err != nil {
+		m.message = fmt.Sprintf("❌ %v", err)
+	}
+	return m
+}
+
+func (m applyPresetModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m applyPresetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			PlaySound(config.ErrorSound)
+			return newProjectSubmenuModel(m.project), nil
+		case "left":
+			m.preset.move(-1)
+			return m.check(), nil
+		case "right":
+			m.preset.move(1)
+			return m.check(), nil
+		case "enter":
+			r, err := app.ApplyPreset(baseDir, m.project.ID, m.preset.value(), nil, false)
+			if err != nil {
+				PlaySound(config.ErrorSound)
+				m.message = fmt.Sprintf("❌ %v", err)
+				return m, nil
+			}
+			PlaySound(config.ConfirmSound)
+			m = m.check()
+			if p, err := app.ReadProjectFile(baseDir, m.project.ID); err == nil {
+				m.project = p
+			}
+			m.message = fmt.Sprintf("✅ Created %d folders and %d files", len(r.MissingFolders), len(r.MissingFiles))
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+func (m applyPresetModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧩 Apply Preset to %s\n\n", m.project.ID)
+	b.WriteString(m.preset.View(true) + "\n\n")
+
+	for _, f := range m.report.MissingFolders {
+		fmt.Fprintf(&b, "  ➕ missing folder %s/\n", f)
+	}
+	for _, f := range m.report.MissingFiles {
+		fmt.Fprintf(&b, "  ➕ missing file %s\n", f)
+	}
+	for _, f := range m.report.ExtraFolders {
+		fmt.Fprintf(&b, "  ⚠️ extra folder %s/ (left alone)\n", f)
+	}
+	if m.report.InSync() && m.message == "" {
+		b.WriteString("  ✅ Project matches the preset\n")
+	}
+
+	b.WriteString("\n[←/→] change preset • [enter] create missing • [esc] back\n")
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+type archiveProjectModel struct {
+	input   textinput.Model
+	remove  bool
+	message string
+	done    bool
+}
+
+func newArchiveProjectModel(id string) archiveProjectModel {
+	ti := textinput.New()
+	ti.Placeholder = "Enter Project ID"
+	ti.CharLimit = 32
+	ti.Width = 30
+	ti.SetValue(id)
+	ti.Focus()
+
+	return archiveProjectModel{input: ti}
+}
+
+func (m archiveProjectModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m archiveProjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.done {
+			if msg.String() == "esc" || msg.String() == "enter" {
+				return mainMenuModel{}, nil
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return mainMenuModel{}, nil
+		case "tab":
+			m.remove = !m.remove
+			return m, nil
+		case "enter":
+			id := app.ValidateID(m.input.Value())
+			if id == "" {
+				PlaySound(config.ErrorSound)
+				m.message = "❌ Invalid ID"
+				return m, nil
+			}
+
+			_, err := app.ArchiveProject(baseDir, id, app.ArchiveOptions{RemoveSource: m.remove})
+			if err != nil {
+				PlaySound(config.ErrorSound)
+				m.message = fmt.Sprintf("❌ %v", err)
+				return m, nil
+			}
+
+			PlaySound(config.ConfirmSound)
+			m.done = true
+			m.message = fmt.Sprintf("📦 Archived %s to %s", id, app.ArchivePath(baseDir, id))
+			if m.remove {
+				m.message += "\n🗑️ Live folder removed"
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m archiveProjectModel) View() string {
+	if m.done {
+		return fmt.Sprintf("%s\n\n[esc] Back to menu", m.message)
+	}
+
+	remove := "❌ Keep"
+	if m.remove {
+		remove = "✅ Remove"
+	}
+
+	var b strings.Builder
+	b.WriteString("📦 Archive Project\n\n")
+	b.WriteString(m.input.View() + "\n\n")
+	fmt.Fprintf(&b, "Live folder after archiving: %s\n", remove)
+	b.WriteString("\n[enter] Archive • [tab] Toggle remove • [esc] Cancel\n")
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}
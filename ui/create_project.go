@@ -16,10 +16,21 @@ type createProjectModel struct {
 	baseDir string
 	done    bool
 	message string
+
+	// The preset picker follows the text inputs in the focus order.
+	preset selector
+	// fields are the configured custom fields; their inputs follow the
+	// fixed ones.
+	fields []app.FieldDef
+	// suggested is the ID filled in from PROJMAN_PROJECT_ID_PATTERN. While
+	// the ID input still holds it the ID is allocated on submit.
+	suggested string
 }
 
+// fixedInputs is how many inputs come before the custom fields.
+const fixedInputs = 4
+
 func newCreateProjectModel() createProjectModel {
-	base := app.GetDefaultBaseDir()
 	fields := []string{"Project ID", "Project Name", "Description", "Tags (comma-separated)"}
 	inputs := make([]textinput.Model, len(fields))
 	for i := range inputs {
@@ -34,17 +45,83 @@ func newCreateProjectModel() createProjectModel {
 		inputs[i] = ti
 	}
 
-	return createProjectModel{
+	custom := app.CustomFields()
+	for _, d := range custom {
+		ti := textinput.New()
+		ti.Prompt = d.Name + ": "
+		ti.Placeholder = d.Hint()
+		if d.Required {
+			ti.Placeholder += " (required)"
+		}
+		ti.CharLimit = 100
+		ti.Width = 40
+		ti.SetValue(d.Default)
+		inputs = append(inputs, ti)
+	}
+
+	m := createProjectModel{
 		inputs:  inputs,
 		focus:   0,
-		baseDir: base,
+		baseDir: baseDir,
+		fields:  custom,
+	}
+
+	presets, err := app.GetAvailablePresets(baseDir)
+	if err != nil {
+		m.message = fmt.Sprintf("⚠️ %v", err)
+		presets = []string{app.DefaultPresetName}
+	}
+	m.preset = newSelector("Preset", presets, config.DefaultPreset)
+	if config.ProjectIDPattern != "" {
+		m.inputs[0].Placeholder = "Project ID (next " + config.ProjectIDPattern + ")"
 	}
+	return m.suggestID()
+}
+
+func (m createProjectModel) fieldValues() map[string]string {
+	fields := map[string]string{}
+	for i, d := range m.fields {
+		if v := strings.TrimSpace(m.inputs[fixedInputs+i].Value()); v != "" {
+			fields[d.Name] = v
+		}
+	}
+	return fields
+}
+
+// suggestID refills the ID input from the ID pattern, which may depend on
+// custom fields, unless the user has typed an ID of their own.
+func (m createProjectModel) suggestID() createProjectModel {
+	if config.ProjectIDPattern == "" || m.inputs[0].Value() != m.suggested {
+		return m
+	}
+	id, err := app.NextProjectID(m.baseDir, m.fieldValues())
+	if err != nil {
+		id = ""
+	}
+	m.suggested = id
+	m.inputs[0].SetValue(id)
+	return m
 }
 
 func (m createProjectModel) Init() tea.Cmd {
 	return textinput.Blink
 }
 
+func (m createProjectModel) onPreset() bool {
+	return m.focus == len(m.inputs)
+}
+
+func (m createProjectModel) moveFocus(delta int) createProjectModel {
+	if !m.onPreset() {
+		m.inputs[m.focus].Blur()
+	}
+	m.focus = (m.focus + delta + len(m.inputs) + 1) % (len(m.inputs) + 1)
+	if !m.onPreset() {
+		m.inputs[m.focus].Focus()
+	}
+	return m.suggestID()
+}
+
 func (m createProjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -52,51 +129,68 @@ func (m createProjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c", "esc":
 			return mainMenuModel{}, nil
 		case "enter":
-			if m.focus == len(m.inputs)-1 {
-				id := app.ValidateID(m.inputs[0].Value())
-				name := m.inputs[1].Value()
-				desc := m.inputs[2].Value()
-				tags := m.inputs[3].Value()
-
-				if id == "" || name == "" {
-					m.message = "❌ ID and Name are required"
-					return m, nil
+			if m.done {
+				return mainMenuModel{}, nil
+			}
+			if m.onPreset() {
+				return m.submit()
+			}
+			return m.moveFocus(1), nil
+		case "tab", "down":
+			return m.moveFocus(1), nil
+		case "shift+tab", "up":
+			return m.moveFocus(-1), nil
+		case "left", "right":
+			if m.onPreset() {
+				if msg.String() == "left" {
+					m.preset.move(-1)
+				} else {
+					m.preset.move(1)
 				}
-
-				app.CreateProject(m.baseDir, app.Params{
-					ID:          id,
-					Name:        name,
-					Description: desc,
-					Tags:        tags,
-					Status:      "active",
-				})
-
-				m.done = true
-				m.message = fmt.Sprintf("✅ Project %s created!", id)
 				return m, nil
 			}
+		}
+	}
 
-			m.inputs[m.focus].Blur()
-			m.focus = (m.focus + 1) % len(m.inputs)
-			m.inputs[m.focus].Focus()
-			return m, nil
+	if m.onPreset() {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
 
-		case "tab", "down":
-			m.inputs[m.focus].Blur()
-			m.focus = (m.focus + 1) % len(m.inputs)
-			m.inputs[m.focus].Focus()
-		case "shift+tab", "up":
-			m.inputs[m.focus].Blur()
-			m.focus = (m.focus - 1 + len(m.inputs)) % len(m.inputs)
-			m.inputs[m.focus].Focus()
-		}
+func (m createProjectModel) submit() (tea.Model, tea.Cmd) {
+	id := app.ValidateID(m.inputs[0].Value())
+	name := m.inputs[1].Value()
+	desc := m.inputs[2].Value()
+	tags := m.inputs[3].Value()
+	autoID := config.ProjectIDPattern != "" && m.inputs[0].Value() == m.suggested
+
+	if (id == "" && !autoID) || name == "" {
+		m.message = "❌ ID and Name are required"
+		return m, nil
 	}
 
-	cmds := make([]tea.Cmd, len(m.inputs))
-	for i := range m.inputs {
-		m.inputs[i], cmds[i] = m.inputs[i].Update(msg)
+	p, err := app.CreateProject(m.baseDir, app.Params{
+		ID:          id,
+		Name:        name,
+		Description: desc,
+		Tags:        tags,
+		Preset:      m.preset.value(),
+		Fields:      m.fieldValues(),
+		AutoID:      autoID,
+	})
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		m.message = fmt.Sprintf("❌ %v", err)
+		return m, nil
 	}
-	return m, tea.Batch(cmds...)
+
+	PlaySound(config.ConfirmSound)
+	m.done = true
+	m.message = fmt.Sprintf("✅ Project %s created!", p.ID)
+	return m, nil
 }
 
 func (m createProjectModel) View() string {
@@ -109,7 +203,8 @@ func (m createProjectModel) View() string {
 	for i := range m.inputs {
 		b.WriteString(m.inputs[i].View() + "\n")
 	}
-	b.WriteString("\n[tab] to switch • [enter] to submit • [esc] cancel\n")
+	b.WriteString(m.preset.View(m.onPreset()) + "\n")
+	b.WriteString("\n[tab] to switch • [←/→] change preset • [enter] on preset to submit • [esc] cancel\n")
 	if m.message != "" {
 		b.WriteString("\n" + m.message + "\n")
 	}
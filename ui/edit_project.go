@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+type editProjectModel struct {
+	project app.Project
+	inputs  []textinput.Model
+	focus   int
+	message string
+
+	// The status is picked from the transitions the lifecycle allows and
+	// sits after the text inputs in the focus order.
+	status selector
+}
+
+func newEditProjectModel(p app.Project) editProjectModel {
+	fields := []string{"Project Name", "Description", "Tags (comma-separated)"}
+	values := []string{p.Name, p.Description, strings.Join(p.Tags, ", ")}
+	inputs := make([]textinput.Model, len(fields))
+	for i := range inputs {
+		ti := textinput.New()
+		ti.Placeholder = fields[i]
+		ti.CharLimit = 100
+		ti.Width = 40
+		ti.SetValue(values[i])
+		inputs[i] = ti
+	}
+	inputs[0].Focus()
+
+	statuses := []string{p.Status}
+	for _, s := range app.AllowedTransitions(p.Status) {
+		if !slices.Contains(statuses, s) {
+			statuses = append(statuses, s)
+		}
+	}
+
+	return editProjectModel{
+		project: p,
+		inputs:  inputs,
+		status:  newSelector("Status", statuses, p.Status),
+	}
+}
+
+func (m editProjectModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m editProjectModel) onStatus() bool {
+	return m.focus == len(m.inputs)
+}
+
+func (m editProjectModel) moveFocus(delta int) editProjectModel {
+	if !m.onStatus() {
+		m.inputs[m.focus].Blur()
+	}
+	m.focus = (m.focus + delta + len(m.inputs) + 1) % (len(m.inputs) + 1)
+	if !m.onStatus() {
+		m.inputs[m.focus].Focus()
+	}
+	return m
+}
+
+func (m editProjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return newProjectSubmenuModel(m.project), nil
+		case "enter":
+			if m.onStatus() {
+				return m.save()
+			}
+			return m.moveFocus(1), nil
+		case "tab", "down":
+			return m.moveFocus(1), nil
+		case "shift+tab", "up":
+			return m.moveFocus(-1), nil
+		case "left", "right":
+			if m.onStatus() {
+				if msg.String() == "left" {
+					m.status.move(-1)
+				} else {
+					m.status.move(1)
+				}
+				return m, nil
+			}
+		}
+	}
+
+	if m.onStatus() {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(msg)
+	return m, cmd
+}
+
+func (m editProjectModel) save() (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(m.inputs[0].Value())
+	desc := m.inputs[1].Value()
+	tags := app.CleanTags(m.inputs[2].Value())
+	status := m.status.value()
+
+	if name == "" {
+		PlaySound(config.ErrorSound)
+		m.message = "❌ Name is required"
+		return m, nil
+	}
+
+	changes := app.Changes{
+		Name:        &name,
+		Description: &desc,
+		Tags:        &tags,
+	}
+	if status != m.project.Status {
+		changes.Status = &status
+	}
+	p, err := app.UpdateProject(baseDir, m.project.ID, changes)
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		m.message = fmt.Sprintf("❌ %v", err)
+		return m, nil
+	}
+
+	PlaySound(config.ConfirmSound)
+	return newProjectSubmenuModel(p), nil
+}
+
+func (m editProjectModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✏️ Edit Project %s\n\n", m.project.ID)
+	for i := range m.inputs {
+		b.WriteString(m.inputs[i].View() + "\n")
+	}
+
+	b.WriteString(m.status.View(m.onStatus()) + "\n")
+
+	b.WriteString("\n[tab] to switch • [←/→] change status • [enter] on status to save • [esc] cancel\n")
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}
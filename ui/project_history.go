@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+const historyPageSize = 12
+
+type projectHistoryModel struct {
+	project app.Project
+	entries []app.JournalEntry
+	offset  int
+	errMsg  string
+}
+
+func newProjectHistoryModel(p app.Project) projectHistoryModel {
+	m := projectHistoryModel{project: p}
+	entries, err := app.ReadJournal(p.Path)
+	if err != nil {
+		m.errMsg = fmt.Sprintf("❌ %v", err)
+	}
+
+	// Newest first, which is what people look for.
+	for i := len(entries) - 1; i >= 0; i-- {
+		m.entries = append(m.entries, entries[i])
+	}
+	return m
+}
+
+func (m projectHistoryModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m projectHistoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q", "b":
+			return newProjectSubmenuModel(m.project), nil
+		case "up", "k":
+			if m.offset > 0 {
+				m.offset--
+				PlaySound(config.NavUpSound)
+			}
+		case "down", "j":
+			if m.offset < len(m.entries)-historyPageSize {
+				m.offset++
+				PlaySound(config.NavDownSound)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m projectHistoryModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🕑 History: %s - %s\n\n", m.project.ID, m.project.Name)
+
+	if m.errMsg != "" {
+		b.WriteString(m.errMsg + "\n\n")
+	}
+	if len(m.entries) == 0 {
+		b.WriteString("📭 No journal entries yet.\n\n[esc] Back\n")
+		return b.String()
+	}
+
+	end := min(m.offset+historyPageSize, len(m.entries))
+	for _, e := range m.entries[m.offset:end] {
+		fmt.Fprintf(&b, "%s  %-10s %-8s %s\n", e.Time, e.User, e.Action, e.Summary())
+	}
+	fmt.Fprintf(&b, "\n%d–%d of %d\n", m.offset+1, end, len(m.entries))
+
+	b.WriteString("\n[↑/↓] Scroll • [esc/b] Back\n")
+	return b.String()
+}
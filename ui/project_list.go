@@ -2,7 +2,6 @@ package ui
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -18,28 +17,19 @@ type projectListModel struct {
 	searching bool
 	cursor    int
 	baseDir   string
+	// warnings describe folders that were skipped while listing.
+	warnings []string
 }
 
 func newProjectListModel() projectListModel {
-	base := app.GetDefaultBaseDir()
-	entries, err := os.ReadDir(base)
+	base := baseDir
+	all, warnings, err := app.ListProjects(base)
 	if err != nil {
 		return projectListModel{baseDir: base}
 	}
 
-	all := []app.Project{}
-	for _, entry := range entries {
-		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-		p, err := app.ReadProjectFile(base, entry.Name())
-		if err == nil {
-			all = append(all, p)
-		}
-	}
-
 	input := textinput.New()
-	input.Placeholder = "Search Project ID..."
+	input.Placeholder = "Search ID, name or tag..."
 	input.CharLimit = 30
 	input.Width = 30
 
@@ -47,7 +37,8 @@ func newProjectListModel() projectListModel {
 		all:       all,
 		filtered:  all,
 		searchBar: input,
-		baseDir:   base}
+		baseDir:   base,
+		warnings:  warnings}
 }
 
 func (m projectListModel) Init() tea.Cmd {
@@ -68,7 +59,7 @@ func (m projectListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.cursor = 0
 			default:
 				m.searchBar, cmd = m.searchBar.Update(msg)
-				m.filtered = filterProjects(m.all, m.searchBar.Value())
+				m.filtered = app.SearchProjects(m.all, m.searchBar.Value())
 				if m.cursor >= len(m.filtered) {
 					m.cursor = 0
 				}
@@ -111,6 +102,13 @@ func (m projectListModel) View() string {
 		b.WriteString(fmt.Sprintf("🔍 %s\n\n", m.searchBar.View()))
 	}
 
+	for _, w := range m.warnings {
+		fmt.Fprintf(&b, "⚠️ %s\n", w)
+	}
+	if len(m.warnings) > 0 {
+		b.WriteString("\n")
+	}
+
 	if len(m.filtered) == 0 {
 		b.WriteString("📭 No projects found.\n\n[esc] Back\n")
 		return b.String()
@@ -128,18 +126,3 @@ func (m projectListModel) View() string {
 	b.WriteString("\n[↑/↓] Navigate • [ctrl+f] Search • [enter/Spacebar] Select • [esc/b] Back/Cancel\n")
 	return b.String()
 }
-
-func filterProjects(projects []app.Project, query string) []app.Project {
-	if query == "" {
-		return projects
-	}
-
-	var filtered []app.Project
-	q := strings.ToUpper(query)
-	for _, p := range projects {
-		if strings.Contains(strings.ToUpper(p.ID), q) {
-			filtered = append(filtered, p)
-		}
-	}
-	return filtered
-}
@@ -2,10 +2,9 @@ package ui
 
 import (
 	"fmt"
-	"os/exec"
-	"runtime"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/thornzero/projman/app"
@@ -18,6 +17,9 @@ type projectSubmenuModel struct {
 
 var submenuItems = []string{
 	"View Status",
+	"Edit Project",
+	"History",
+	"Apply Preset",
 	"Archive Project",
 	"Open Folder",
 	"Back",
@@ -50,20 +52,19 @@ func (m projectSubmenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.choice {
 			case 0: // View Status
 				return viewProjectModel{project: &m.project, done: true}, nil
-			case 1: // Archive
-				_ = app.ZipProjectFolder(m.project.Path, m.project.Path+".zip")
-				return mainMenuModel{}, nil
-			case 2: // Open Folder
+			case 1: // Edit
+				return newEditProjectModel(m.project), textinput.Blink
+			case 2: // History
+				return newProjectHistoryModel(m.project), nil
+			case 3: // Apply Preset
+				return newApplyPresetModel(m.project), nil
+			case 4: // Archive
+				return newArchiveProjectModel(m.project.ID), textinput.Blink
+			case 5: // Open Folder
 				PlaySound(config.ConfirmSound)
-				openCmd := "xdg-open"
-				if runtime.GOOS == "darwin" {
-					openCmd = "open"
-				} else if runtime.GOOS == "windows" {
-					openCmd = "explorer"
-				}
-				_ = exec.Command(openCmd, m.project.Path).Start()
+				_ = app.OpenFolder(m.project.Path)
 				return mainMenuModel{}, nil
-			case 3: // Back
+			case 6: // Back
 				PlaySound(config.ErrorSound)
 				return mainMenuModel{}, nil
 			}
@@ -0,0 +1,43 @@
+package ui
+
+import "fmt"
+
+// selector is a single-choice field cycled with the left and right keys.
+type selector struct {
+	label   string
+	options []string
+	index   int
+}
+
+func newSelector(label string, options []string, selected string) selector {
+	s := selector{label: label, options: options}
+	for i, o := range options {
+		if o == selected {
+			s.index = i
+		}
+	}
+	return s
+}
+
+func (s selector) value() string {
+	if len(s.options) == 0 {
+		return ""
+	}
+	return s.options[s.index]
+}
+
+func (s *selector) move(delta int) {
+	if len(s.options) == 0 {
+		return
+	}
+	s.index = (s.index + delta + len(s.options)) % len(s.options)
+	PlaySound(config.NavDownSound)
+}
+
+func (s selector) View(focused bool) string {
+	prefix := "  "
+	if focused {
+		prefix = "👉"
+	}
+	return fmt.Sprintf("%s %s: ◀ %s ▶", prefix, s.label, s.value())
+}
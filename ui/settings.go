@@ -2,54 +2,204 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
 )
 
+type settingKind int
+
+const (
+	settingToggle settingKind = iota
+	settingText
+	settingChoice
+	settingAction
+)
+
+// settingField is one row of the settings screen. Text rows edit through
+// input, choice rows through choice; key names the PROJMAN_* setting the
+// row edits so overrides can be pointed out.
+type settingField struct {
+	label  string
+	key    string
+	kind   settingKind
+	on     bool
+	input  textinput.Model
+	choice selector
+}
+
 type settingsModel struct {
 	cursor  int
-	toggles []bool
+	fields  []settingField
+	message string
 }
 
-var settingsItems = []string{
-	"Enable Sound Effects",
-	"Back to Menu",
-}
+const (
+	fieldSounds = iota
+	fieldBaseDir
+	fieldNavUp
+	fieldNavDown
+	fieldSelect
+	fieldConfirm
+	fieldError
+	fieldTaggingFormat
+	fieldTaggingStart
+	fieldDesignationForm
+	fieldIDPattern
+	fieldDefaultPreset
+	fieldDefaultStatus
+	fieldSave
+	fieldBack
+)
 
 func newSettingsModel() settingsModel {
-	return settingsModel{
-		toggles: []bool{config.SoundsEnabled},
+	text := func(label, key, value string) settingField {
+		ti := textinput.New()
+		ti.CharLimit = 200
+		ti.Width = 40
+		ti.SetValue(value)
+		return settingField{label: label, key: key, kind: settingText, input: ti}
 	}
+
+	presets, err := app.GetAvailablePresets(baseDir)
+	if err != nil || !slices.Contains(presets, config.DefaultPreset) {
+		presets = append(presets, config.DefaultPreset)
+	}
+
+	m := settingsModel{fields: []settingField{
+		{label: "Sound Effects", key: "PROJMAN_SOUND_ENABLED", kind: settingToggle, on: config.SoundsEnabled},
+		text("Base Directory", "PROJMAN_BASE_DIR", config.BaseDir),
+		text("Nav Up Sound", "PROJMAN_SOUND_NAV_UP", config.NavUpSound),
+		text("Nav Down Sound", "PROJMAN_SOUND_NAV_DOWN", config.NavDownSound),
+		text("Select Sound", "PROJMAN_SOUND_SELECT", config.SelectSound),
+		text("Confirm Sound", "PROJMAN_SOUND_CONFIRM", config.ConfirmSound),
+		text("Error Sound", "PROJMAN_SOUND_ERROR", config.ErrorSound),
+		text("Tagging Format", "PROJMAN_TAGGING_FORMAT", config.TaggingFormat),
+		text("Tagging Start", "PROJMAN_TAGGING_START", strconv.Itoa(config.TaggingStart)),
+		{label: "Designation Form", key: "PROJMAN_DESIGNATION_FORM", kind: settingChoice, choice: newSelector("Designation Form", []string{app.DesignationShort, app.DesignationLong}, config.DesignationForm)},
+		text("Project ID Pattern", "PROJMAN_PROJECT_ID_PATTERN", config.ProjectIDPattern),
+		{label: "Default Preset", key: "PROJMAN_DEFAULT_PRESET", kind: settingChoice, choice: newSelector("Default Preset", presets, config.DefaultPreset)},
+		{label: "Default Status", key: "PROJMAN_DEFAULT_STATUS", kind: settingChoice, choice: newSelector("Default Status", config.Statuses, config.DefaultStatus)},
+		{label: "💾 Save", kind: settingAction},
+		{label: "Back to Menu", kind: settingAction},
+	}}
+	if err != nil {
+		m.message = fmt.Sprintf("⚠️ %v", err)
+	}
+	return m
 }
 
 func (m settingsModel) Init() tea.Cmd {
 	return nil
 }
 
+func (m settingsModel) moveCursor(delta int) settingsModel {
+	m.fields[m.cursor].input.Blur()
+	m.cursor = (m.cursor + delta + len(m.fields)) % len(m.fields)
+	if m.fields[m.cursor].kind == settingText {
+		m.fields[m.cursor].input.Focus()
+	}
+	return m
+}
+
 func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	f := &m.fields[m.cursor]
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			return mainMenuModel{}, nil
-		case "up", "k":
-			if m.cursor > 0 {
-				m.cursor--
+		case "up", "shift+tab":
+			PlaySound(config.NavUpSound)
+			return m.moveCursor(-1), nil
+		case "down", "tab":
+			PlaySound(config.NavDownSound)
+			return m.moveCursor(1), nil
+		case "left", "right":
+			if f.kind == settingChoice {
+				if msg.String() == "left" {
+					f.choice.move(-1)
+				} else {
+					f.choice.move(1)
+				}
+				return m, nil
 			}
-		case "down", "j":
-			if m.cursor < len(settingsItems)-1 {
-				m.cursor++
-			}
-		case "enter":
-			if m.cursor == 0 {
-				m.toggles[0] = !m.toggles[0]
-				config.SoundsEnabled = m.toggles[0]
-			} else if m.cursor == 1 {
+		case "enter", " ":
+			switch {
+			case f.kind == settingToggle:
+				f.on = !f.on
+				return m, nil
+			case m.cursor == fieldSave:
+				return m.save()
+			case m.cursor == fieldBack:
 				return mainMenuModel{}, nil
+			case msg.String() == "enter":
+				return m.moveCursor(1), nil
+			}
+		}
+	}
+
+	if f.kind != settingText {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(msg)
+	return m, cmd
+}
+
+func (m settingsModel) save() (tea.Model, tea.Cmd) {
+	value := func(i int) string { return strings.TrimSpace(m.fields[i].input.Value()) }
+
+	start, err := strconv.Atoi(value(fieldTaggingStart))
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		m.message = fmt.Sprintf("❌ Tagging start %q is not a number", value(fieldTaggingStart))
+		return m, nil
+	}
+
+	c := config
+	c.SoundsEnabled = m.fields[fieldSounds].on
+	c.BaseDir = value(fieldBaseDir)
+	c.NavUpSound = value(fieldNavUp)
+	c.NavDownSound = value(fieldNavDown)
+	c.SelectSound = value(fieldSelect)
+	c.ConfirmSound = value(fieldConfirm)
+	c.ErrorSound = value(fieldError)
+	c.TaggingFormat = value(fieldTaggingFormat)
+	c.TaggingStart = start
+	c.DesignationForm = m.fields[fieldDesignationForm].choice.value()
+	c.ProjectIDPattern = value(fieldIDPattern)
+	c.DefaultPreset = m.fields[fieldDefaultPreset].choice.value()
+	c.DefaultStatus = m.fields[fieldDefaultStatus].choice.value()
+
+	saved, err := app.SaveConfig(c)
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		m.message = fmt.Sprintf("❌ %v", err)
+		return m, nil
+	}
+	config, baseDir = saved, saved.BaseDir
+
+	var overridden []string
+	for _, v := range app.ConfigValues() {
+		if v.Origin == app.OriginEnv || v.Origin == app.OriginFlag {
+			for _, f := range m.fields {
+				if f.key == v.Key {
+					overridden = append(overridden, f.label)
+				}
 			}
 		}
 	}
+	PlaySound(config.ConfirmSound)
+	m.message = "✅ Settings saved"
+	if len(overridden) > 0 {
+		m.message += fmt.Sprintf(" (⚠️ still overridden by env or flags: %s)", strings.Join(overridden, ", "))
+	}
 	return m, nil
 }
 
@@ -57,24 +207,33 @@ func (m settingsModel) View() string {
 	var b strings.Builder
 	b.WriteString("⚙️ Settings\n\n")
 
-	for i, item := range settingsItems {
+	for i, f := range m.fields {
 		prefix := "  "
 		if m.cursor == i {
 			prefix = "👉"
 		}
-
-		value := ""
-		if i == 0 {
-			if m.toggles[0] {
+		switch f.kind {
+		case settingToggle:
+			value := "❌ Off"
+			if f.on {
 				value = "✅ On"
-			} else {
-				value = "❌ Off"
 			}
+			fmt.Fprintf(&b, "%s %s %s\n", prefix, f.label, value)
+		case settingText:
+			fmt.Fprintf(&b, "%s %-20s %s\n", prefix, f.label+":", f.input.View())
+		case settingChoice:
+			b.WriteString(f.choice.View(m.cursor == i) + "\n")
+		case settingAction:
+			if i == fieldSave {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s %s\n", prefix, f.label)
 		}
-
-		b.WriteString(fmt.Sprintf("%s %s %s\n", prefix, item, value))
 	}
 
-	b.WriteString("\n[↑/↓] Navigate • [Enter] Toggle/Select • [Esc] Back\n")
+	b.WriteString("\n[↑/↓] Navigate • [Enter] Toggle/Select • [←/→] Change choice • [Esc] Back without saving\n")
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
 	return b.String()
 }
@@ -0,0 +1,353 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+type wizardStep int
+
+const (
+	stepBaseDir wizardStep = iota
+	stepPresets
+	stepIDPattern
+	stepTagging
+	stepImport
+	stepConfirm
+	stepDone
+)
+
+// setupWizardModel walks through a first-time setup and writes the user
+// config file at the end. standalone is set for "projman init", which
+// quits when done instead of carrying on to the main menu.
+type setupWizardModel struct {
+	step       wizardStep
+	standalone bool
+	message    string
+
+	input textinput.Model
+	cfg   app.Config
+
+	// Presets step: starter presets to write, plus the default pick.
+	starters   []string
+	create     []bool
+	existing   []string
+	preset     selector
+	onSelector bool
+
+	// Import step: folders without a project.yaml.
+	folders []string
+	adopt   []bool
+	cursor  int
+
+	results []string
+}
+
+func newSetupWizardModel(standalone bool) setupWizardModel {
+	m := setupWizardModel{standalone: standalone, cfg: config}
+	m.input = textinput.New()
+	m.input.CharLimit = 200
+	m.input.Width = 50
+	return m.show(stepBaseDir)
+}
+
+func (m setupWizardModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m setupWizardModel) fail(err error) (tea.Model, tea.Cmd) {
+	PlaySound(config.ErrorSound)
+	m.message = fmt.Sprintf("❌ %v", err)
+	return m, nil
+}
+
+// presetChoices is what the default preset can be: the presets already in
+// the base directory plus the starters about to be written.
+func (m setupWizardModel) presetChoices() []string {
+	choices := slices.Clone(m.existing)
+	for i, name := range m.starters {
+		if m.create[i] && !slices.Contains(choices, name) {
+			choices = append(choices, name)
+		}
+	}
+	return choices
+}
+
+// show moves to step and loads the text input with that step's value.
+func (m setupWizardModel) show(step wizardStep) setupWizardModel {
+	m.step, m.message = step, ""
+	m.input.Blur()
+	switch step {
+	case stepBaseDir:
+		m.input.SetValue(m.cfg.BaseDir)
+		m.input.Placeholder = "e.g. ~/Projects"
+	case stepIDPattern:
+		m.input.SetValue(m.cfg.ProjectIDPattern)
+		m.input.Placeholder = "e.g. CP-{seq:04} (leave empty to type IDs yourself)"
+	case stepTagging:
+		m.input.SetValue(m.cfg.TaggingFormat)
+		m.input.Placeholder = "e.g. {category}-{subcat}-{id}"
+	default:
+		return m
+	}
+	m.input.Focus()
+	return m
+}
+
+func (m setupWizardModel) next() (tea.Model, tea.Cmd) {
+	value := strings.TrimSpace(m.input.Value())
+
+	switch m.step {
+	case stepBaseDir:
+		if value == "" {
+			return m.fail(fmt.Errorf("a base directory is required"))
+		}
+		existing, err := app.GetAvailablePresets(app.ExpandHome(value))
+		if err != nil {
+			return m.fail(err)
+		}
+		m.cfg.BaseDir, m.existing = value, existing
+		m.starters = app.StarterPresetNames()
+		m.create = make([]bool, len(m.starters))
+		for i, name := range m.starters {
+			m.create[i] = !slices.Contains(existing, name)
+		}
+		m.preset = newSelector("Default Preset", m.presetChoices(), m.cfg.DefaultPreset)
+		m.cursor, m.onSelector = 0, false
+
+	case stepPresets:
+		m.cfg.DefaultPreset = m.preset.value()
+
+	case stepIDPattern:
+		if err := app.ValidateIDPattern(value, m.cfg.Fields); err != nil {
+			return m.fail(err)
+		}
+		m.cfg.ProjectIDPattern = value
+
+	case stepTagging:
+		if err := app.ValidateTaggingFormat(value); err != nil {
+			return m.fail(err)
+		}
+		m.cfg.TaggingFormat = value
+		folders, err := app.ImportCandidates(app.ExpandHome(m.cfg.BaseDir))
+		if err != nil {
+			return m.fail(err)
+		}
+		m.folders, m.adopt, m.cursor = folders, make([]bool, len(folders)), 0
+
+	case stepConfirm:
+		return m.finish()
+
+	case stepDone:
+		if m.standalone {
+			return m, tea.Quit
+		}
+		return mainMenuModel{}, nil
+	}
+	return m.show(m.step + 1), textinput.Blink
+}
+
+// finish writes the starter presets and config, then imports the chosen
+// folders. Presets go first so the default preset validates.
+func (m setupWizardModel) finish() (tea.Model, tea.Cmd) {
+	base := app.ExpandHome(m.cfg.BaseDir)
+	for i, name := range m.starters {
+		if !m.create[i] {
+			continue
+		}
+		if err := app.WriteStarterPreset(base, name); err != nil {
+			return m.fail(err)
+		}
+		m.results = append(m.results, fmt.Sprintf("🧩 Wrote preset %s", name))
+	}
+
+	saved, err := app.InitConfig(m.cfg)
+	if err != nil {
+		return m.fail(err)
+	}
+	config, baseDir = saved, saved.BaseDir
+	path, _ := app.UserConfigPath()
+	m.results = append(m.results, fmt.Sprintf("⚙️ Wrote config to %s", path))
+
+	for i, dir := range m.folders {
+		if !m.adopt[i] {
+			continue
+		}
+		if p, err := app.ImportProject(baseDir, dir); err != nil {
+			m.results = append(m.results, fmt.Sprintf("❌ %v", err))
+		} else {
+			m.results = append(m.results, fmt.Sprintf("📥 Imported %s", p.ID))
+		}
+	}
+
+	PlaySound(config.ConfirmSound)
+	m.step = stepDone
+	return m, nil
+}
+
+func (m setupWizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		if m.step > stepBaseDir && m.step < stepDone {
+			return m.show(m.step - 1), textinput.Blink
+		}
+		return m, tea.Quit
+	case "enter":
+		if m.step == stepPresets && !m.onSelector {
+			m.onSelector = true
+			return m, nil
+		}
+		return m.next()
+	}
+
+	switch m.step {
+	case stepBaseDir, stepIDPattern, stepTagging:
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+
+	case stepPresets:
+		switch key.String() {
+		case "up", "k":
+			m.onSelector = false
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.starters)-1 {
+				m.cursor++
+			} else {
+				m.onSelector = true
+			}
+		case " ":
+			if !m.onSelector {
+				m.create[m.cursor] = !m.create[m.cursor]
+				m.preset = newSelector("Default Preset", m.presetChoices(), m.preset.value())
+			}
+		case "left":
+			m.preset.move(-1)
+		case "right":
+			m.preset.move(1)
+		}
+
+	case stepImport:
+		switch key.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.folders)-1 {
+				m.cursor++
+			}
+		case " ":
+			if len(m.adopt) > 0 {
+				m.adopt[m.cursor] = !m.adopt[m.cursor]
+			}
+		case "a":
+			all := !slices.Contains(m.adopt, false)
+			for i := range m.adopt {
+				m.adopt[i] = !all
+			}
+		}
+	}
+	return m, nil
+}
+
+func checkbox(on bool) string {
+	if on {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func (m setupWizardModel) View() string {
+	var b strings.Builder
+	b.WriteString("🧭 Projman Setup\n\n")
+
+	switch m.step {
+	case stepBaseDir:
+		b.WriteString("Where should projects live? The folder is created if it doesn't exist.\n\n")
+		b.WriteString(m.input.View() + "\n")
+		b.WriteString("\n[enter] next • [esc] quit\n")
+
+	case stepPresets:
+		b.WriteString("Starter presets to add (existing presets are kept):\n\n")
+		for i, name := range m.starters {
+			prefix := "  "
+			if i == m.cursor && !m.onSelector {
+				prefix = "👉"
+			}
+			note := ""
+			if slices.Contains(m.existing, name) {
+				note = " (already exists)"
+			}
+			fmt.Fprintf(&b, "%s %s %s%s\n", prefix, checkbox(m.create[i]), name, note)
+		}
+		b.WriteString("\n" + m.preset.View(m.onSelector) + "\n")
+		b.WriteString("\n[space] toggle • [←/→] default preset • [enter] next • [esc] back\n")
+
+	case stepIDPattern:
+		b.WriteString("Project ID pattern for automatic IDs ({seq:04}, {yy}, {yyyy}, {mm} or a custom field):\n\n")
+		b.WriteString(m.input.View() + "\n")
+		b.WriteString("\n[enter] next • [esc] back\n")
+
+	case stepTagging:
+		b.WriteString("Tagging format for generated tags ({category}, {subcat}, {id:04}, CSV columns, [optional]):\n\n")
+		b.WriteString(m.input.View() + "\n")
+		b.WriteString("\n[enter] next • [esc] back\n")
+
+	case stepImport:
+		if len(m.folders) == 0 {
+			b.WriteString("No existing folders to import.\n")
+		} else {
+			b.WriteString("Existing folders without a project.yaml can be imported as projects:\n\n")
+			for i, dir := range m.folders {
+				prefix := "  "
+				if i == m.cursor {
+					prefix = "👉"
+				}
+				fmt.Fprintf(&b, "%s %s %s\n", prefix, checkbox(m.adopt[i]), dir)
+			}
+		}
+		b.WriteString("\n[space] toggle • [a] all • [enter] next • [esc] back\n")
+
+	case stepConfirm:
+		fmt.Fprintf(&b, "  Base directory:     %s\n", m.cfg.BaseDir)
+		fmt.Fprintf(&b, "  Default preset:     %s\n", m.cfg.DefaultPreset)
+		fmt.Fprintf(&b, "  Project ID pattern: %s\n", m.cfg.ProjectIDPattern)
+		fmt.Fprintf(&b, "  Tagging format:     %s\n", m.cfg.TaggingFormat)
+		imports := 0
+		for _, on := range m.adopt {
+			if on {
+				imports++
+			}
+		}
+		fmt.Fprintf(&b, "  Folders to import:  %d\n", imports)
+		b.WriteString("\n[enter] write config • [esc] back\n")
+
+	case stepDone:
+		for _, r := range m.results {
+			b.WriteString(r + "\n")
+		}
+		b.WriteString("\n✅ Setup complete. [enter] continue\n")
+	}
+
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,17 +15,19 @@ type toolsModel struct {
 	mode       string
 	inputCSV   textinput.Model
 	outputYAML textinput.Model
+	lintFile   textinput.Model
 	message    string
 }
 
 var toolItems = []string{
 	"🏷️ Generate Tags",
+	"🔍 Lint Tags",
 	"⬅️ Back",
 }
 
 func newToolsModel() toolsModel {
 	input := textinput.New()
-	input.Placeholder = "Path to input CSV"
+	input.Placeholder = "Path to input CSV or nested tag YAML"
 	input.CharLimit = 128
 	input.Width = 40
 
@@ -33,9 +36,15 @@ func newToolsModel() toolsModel {
 	out.CharLimit = 128
 	out.Width = 40
 
+	lint := textinput.New()
+	lint.Placeholder = "Tag YAML or CSV file to check"
+	lint.CharLimit = 128
+	lint.Width = 40
+
 	return toolsModel{
 		inputCSV:   input,
 		outputYAML: out,
+		lintFile:   lint,
 	}
 }
 
@@ -53,11 +62,11 @@ func (m toolsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				csv := m.inputCSV.Value()
 				out := m.outputYAML.Value()
 				if csv != "" && out != "" {
-					err := app.GenerateTags(csv, out)
+					r, err := app.GenerateTags(csv, out)
 					if err != nil {
 						m.message = "❌ Failed: " + err.Error()
 					} else {
-						m.message = "✅ Tags generated successfully."
+						m.message = fmt.Sprintf("✅ Generated %d tags (%d new, %d retired) — registry %s", r.Tags, r.New, r.Retired, r.Registry)
 					}
 					m.mode = ""
 				}
@@ -69,6 +78,19 @@ func (m toolsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.outputYAML, _ = m.outputYAML.Update(msg)
 			}
 			return m, nil
+		case "lint":
+			switch msg.String() {
+			case "enter":
+				if file := m.lintFile.Value(); file != "" {
+					m.message = lintReport(file)
+				}
+			case "esc":
+				m.mode = ""
+				m.message = ""
+			default:
+				m.lintFile, _ = m.lintFile.Update(msg)
+			}
+			return m, nil
 		}
 
 		switch msg.String() {
@@ -86,6 +108,10 @@ func (m toolsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.mode = "generate"
 				m.inputCSV.Focus()
 			case 1:
+				m.mode = "lint"
+				m.message = ""
+				m.lintFile.Focus()
+			case 2:
 				return mainMenuModel{}, nil
 			}
 		case "esc", "q":
@@ -98,13 +124,21 @@ func (m toolsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m toolsModel) View() string {
 	if m.mode == "generate" {
 		return fmt.Sprintf(
-			"🛠 Generate Tags\n\nInput CSV:\n%s\n\nOutput YAML:\n%s\n\n[enter] Generate • [esc] Cancel\n\n%s",
+			"🛠 Generate Tags\n\nInput CSV or YAML:\n%s\n\nOutput YAML:\n%s\n\n[enter] Generate • [esc] Cancel\n\n%s",
 			m.inputCSV.View(),
 			m.outputYAML.View(),
 			m.message,
 		)
 	}
 
+	if m.mode == "lint" {
+		return fmt.Sprintf(
+			"🔍 Lint Tags\n\nFile:\n%s\n\n[enter] Check • [esc] Back\n\n%s",
+			m.lintFile.View(),
+			m.message,
+		)
+	}
+
 	s := "🧰 Tools\n\n"
 	for i, item := range toolItems {
 		prefix := "  "
@@ -116,3 +150,35 @@ func (m toolsModel) View() string {
 	s += "\n[↑/↓] Navigate • [Enter] Select • [Esc] Back\n"
 	return s
 }
+
+// lintReport checks file against its project's tag rules and formats the
+// result for the lint screen.
+func lintReport(file string) string {
+	rules, source, err := app.LoadTagRules(file)
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		return fmt.Sprintf("❌ %v", err)
+	}
+	if source == "" {
+		source = "built-in rules (uniqueness only)"
+	}
+	r, err := app.LintTags(file, rules)
+	if err != nil {
+		PlaySound(config.ErrorSound)
+		return fmt.Sprintf("❌ %v", err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Rules: %s\n\n", source)
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "  line %d: %s: %s\n", issue.Line, issue.Tag, issue.Message)
+	}
+	if len(r.Issues) > 0 {
+		PlaySound(config.ErrorSound)
+		fmt.Fprintf(&b, "\n❌ %d problems in %d tags", len(r.Issues), r.Tags)
+	} else {
+		PlaySound(config.ConfirmSound)
+		fmt.Fprintf(&b, "✅ %d tags OK", r.Tags)
+	}
+	return b.String()
+}
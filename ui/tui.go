@@ -2,9 +2,9 @@ package ui
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
 	core "github.com/thornzero/projman/app"
@@ -18,6 +18,7 @@ const (
 	optionViewProject
 	optionArchiveProject
 	optionTools
+	optionWorkspace
 	optionSettings
 	optionQuit
 )
@@ -28,11 +29,15 @@ var menuItems = []string{
 	"🔍 View Project Status",
 	"📦 Archive Project",
 	"🧰 Tools",
+	"🗂 Switch Workspace",
 	"⚙️ Settings",
 	"❌ Quit",
 }
 
-var config = core.LoadConfig()
+var (
+	config  core.Config
+	baseDir string
+)
 
 type mainMenuModel struct {
 	cursor int
@@ -70,9 +75,15 @@ func (m mainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case optionViewProject:
 				return newViewProjectModel(), nil
 
+			case optionArchiveProject:
+				return newArchiveProjectModel(""), textinput.Blink
+
 			case optionTools:
 				return newToolsModel(), nil
 
+			case optionWorkspace:
+				return newWorkspaceModel(), nil
+
 			case optionSettings:
 				return newSettingsModel(), nil
 
@@ -87,7 +98,11 @@ func (m mainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m mainMenuModel) View() string {
 	var b strings.Builder
-	b.WriteString("🛠 Projman\n\nUse ↑/↓ to navigate and [Enter] to select\n\n")
+	workspace := config.Workspace
+	if workspace == "" {
+		workspace = core.DefaultWorkspaceName
+	}
+	fmt.Fprintf(&b, "🛠 Projman — %s (%s)\n\nUse ↑/↓ to navigate and [Enter] to select\n\n", workspace, baseDir)
 	for i, item := range menuItems {
 		cursor := " "
 		if m.cursor == i {
@@ -98,9 +113,24 @@ func (m mainMenuModel) View() string {
 	return b.String()
 }
 
-func Tui() {
-	p := tea.NewProgram(mainMenuModel{})
-	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+// Tui runs the interactive menu with an already loaded configuration.
+func Tui(cfg core.Config) error {
+	config, baseDir = cfg, cfg.BaseDir
+
+	var start tea.Model = mainMenuModel{}
+	if core.FirstRun() {
+		start = newSetupWizardModel(false)
 	}
+	p := tea.NewProgram(start)
+	_, err := p.Run()
+	return err
+}
+
+// Setup runs only the first-run wizard, for "projman init".
+func Setup(cfg core.Config) error {
+	config, baseDir = cfg, cfg.BaseDir
+
+	p := tea.NewProgram(newSetupWizardModel(true))
+	_, err := p.Run()
+	return err
 }
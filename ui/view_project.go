@@ -27,7 +27,7 @@ func newViewProjectModel() viewProjectModel {
 
 	return viewProjectModel{
 		input:   ti,
-		baseDir: app.GetDefaultBaseDir(),
+		baseDir: baseDir,
 	}
 }
 
@@ -77,7 +77,15 @@ func (m viewProjectModel) View() string {
 		b.WriteString(fmt.Sprintf("Status:      %s\n", p.Status))
 		b.WriteString(fmt.Sprintf("Tags:        %s\n", strings.Join(p.Tags, ", ")))
 		b.WriteString(fmt.Sprintf("Created At:  %s\n", p.CreatedAt))
+		if p.UpdatedAt != "" {
+			b.WriteString(fmt.Sprintf("Updated At:  %s\n", p.UpdatedAt))
+		}
 		b.WriteString(fmt.Sprintf("Path:        %s\n", p.Path))
+		for _, d := range app.CustomFields() {
+			if v, ok := p.Fields[d.Name]; ok {
+				b.WriteString(fmt.Sprintf("%-13s%s\n", d.Name+":", v))
+			}
+		}
 		b.WriteString("\n[esc] Back to menu")
 		return b.String()
 	}
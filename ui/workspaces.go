@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/thornzero/projman/app"
+)
+
+// workspaceModel switches the running program to another workspace. The
+// choice lasts for this session, like the -workspace flag.
+type workspaceModel struct {
+	names   []string
+	cursor  int
+	message string
+}
+
+func newWorkspaceModel() workspaceModel {
+	m := workspaceModel{names: app.WorkspaceNames()}
+	for i, name := range m.names {
+		if name == config.Workspace {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+func (m workspaceModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m workspaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			PlaySound(config.ErrorSound)
+			return mainMenuModel{}, nil
+		case "up", "k":
+			PlaySound(config.NavUpSound)
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			PlaySound(config.NavDownSound)
+			if m.cursor < len(m.names)-1 {
+				m.cursor++
+			}
+		case "enter":
+			cfg, err := app.SwitchWorkspace(m.names[m.cursor])
+			if err != nil {
+				PlaySound(config.ErrorSound)
+				m.message = fmt.Sprintf("❌ %v", err)
+				return m, nil
+			}
+			config, baseDir = cfg, cfg.BaseDir
+			PlaySound(config.ConfirmSound)
+			return mainMenuModel{}, nil
+		}
+	}
+	return m, nil
+}
+
+func (m workspaceModel) View() string {
+	var b strings.Builder
+	b.WriteString("🗂 Switch Workspace\n\n")
+	for i, name := range m.names {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = "👉"
+		}
+		current := ""
+		if name == config.Workspace || (config.Workspace == "" && name == app.DefaultWorkspaceName) {
+			current = " (current)"
+		}
+		fmt.Fprintf(&b, "%s %s%s\n", prefix, name, current)
+	}
+	if len(m.names) == 1 {
+		b.WriteString("\nAdd workspaces with PROJMAN_WORKSPACES and PROJMAN_WORKSPACE_<NAME>_BASE_DIR.\n")
+	}
+	b.WriteString("\n[↑/↓] Navigate • [Enter] Switch • [Esc] Back\n")
+	if m.message != "" {
+		b.WriteString("\n" + m.message + "\n")
+	}
+	return b.String()
+}